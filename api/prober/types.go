@@ -27,14 +27,82 @@ type Config struct {
 	KCMNodeMonitorGraceDuration *metav1.Duration `json:"kcmNodeMonitorGraceDuration,omitempty"`
 	// NodeLeaseFailureFraction is used to determine the maximum number of leases that can be expired for a lease probe to succeed.
 	NodeLeaseFailureFraction *float64 `json:"nodeLeaseFailureFraction,omitempty"`
+	// NodeReadinessGate if set, holds back a scale-up of the dependent resources after a successful lease probe until enough shoot nodes are ready.
+	// If it is not set, then the dependent resources are scaled up as soon as the lease probe succeeds.
+	NodeReadinessGate *NodeReadinessGate `json:"nodeReadinessGate,omitempty"`
+	// MaxScaledDownDuration if set, is the maximum duration for which the dependent resources are kept scaled down while the lease probe fails.
+	// Once it is exceeded, the dependent resources are restored and not scaled down again until the lease probe has succeeded.
+	// If it is not set, then the dependent resources are kept scaled down for as long as the lease probe fails.
+	MaxScaledDownDuration *metav1.Duration `json:"maxScaledDownDuration,omitempty"`
+	// ScaleHooks are HTTP endpoints which are called before and after each scale-up and scale-down of the dependent resources.
+	ScaleHooks []ScaleHook `json:"scaleHooks,omitempty"`
+	// BlastRadiusGuard if set, holds back scale-downs across all shoots of the seed once too many probers want to scale down within a window.
+	// Unlike the other fields it is not applied per shoot, it is shared by all probers.
+	BlastRadiusGuard *BlastRadiusGuard `json:"blastRadiusGuard,omitempty"`
+}
+
+// BlastRadiusGuard captures the configuration of a seed-wide guard against mass scale-downs. Every prober decides in isolation,
+// however a seed-side problem (e.g. seed DNS, the egress path or the network of DWD itself) lets the lease probes of all shoots fail
+// at once. If more probers than allowed want to scale down within Window, then this is treated as a probable seed-side fault and
+// further scale-downs are held back until the number of probers which want to scale down falls within the limits again.
+// At least one of MaxScaleDowns and MaxScaleDownFraction must be set.
+type BlastRadiusGuard struct {
+	// Window is the duration within which probers which want to scale down are counted. If not specified its default value will be 5m.
+	Window *metav1.Duration `json:"window,omitempty"`
+	// MaxScaleDowns is the maximum number of probers which are allowed to scale down within Window.
+	MaxScaleDowns *int `json:"maxScaleDowns,omitempty"`
+	// MaxScaleDownFraction is the maximum fraction of all registered probers which are allowed to scale down within Window.
+	MaxScaleDownFraction *float64 `json:"maxScaleDownFraction,omitempty"`
+}
+
+// ScaleHookFailurePolicy defines how a failed call to a ScaleHook is handled.
+type ScaleHookFailurePolicy string
+
+const (
+	// ScaleHookFailurePolicyIgnore ignores a failed call to a ScaleHook, the scaling of the dependent resources is not affected.
+	ScaleHookFailurePolicyIgnore ScaleHookFailurePolicy = "Ignore"
+	// ScaleHookFailurePolicyBlock fails the scale operation if a call to a ScaleHook fails. If the call before the scaling fails,
+	// then the dependent resources are not scaled. The scale operation is re-attempted with the next probe.
+	ScaleHookFailurePolicyBlock ScaleHookFailurePolicy = "Block"
+)
+
+// ScaleHook captures an HTTP endpoint which is called with a JSON payload before and after the dependent resources are scaled.
+type ScaleHook struct {
+	// Name identifies the hook.
+	Name string `json:"name"`
+	// URL is the HTTP(S) endpoint to which the payload is posted.
+	URL string `json:"url"`
+	// Timeout is the timeout for a single call to the hook. If not specified its default value will be 5s.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// FailurePolicy defines how a failed call to the hook is handled. If not specified its default value will be Ignore.
+	FailurePolicy *ScaleHookFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// NodeReadinessGate captures the configuration of a gate which has to be passed before the dependent resources are scaled up.
+// Fresh node leases only indicate that the kubelets can reach the shoot control plane again. Scaling up resources like
+// machine-controller-manager while many nodes still report NotReady can result in machines being replaced unnecessarily.
+type NodeReadinessGate struct {
+	// ReadyNodeFraction is the minimum fraction of the shoot nodes which need to have the condition Ready=True for the gate to pass.
+	ReadyNodeFraction *float64 `json:"readyNodeFraction,omitempty"`
+	// SettlePeriod is the duration for which ReadyNodeFraction needs to be met continuously before the gate passes.
+	SettlePeriod *metav1.Duration `json:"settlePeriod,omitempty"`
 }
 
 // DependentResourceInfo captures a dependent resource which should be scaled
 type DependentResourceInfo struct {
-	// Ref identifies a resource
-	Ref *autoscalingv1.CrossVersionObjectReference `json:"ref"`
+	// Ref identifies a resource. Exactly one of Ref and Selector should be set.
+	Ref *autoscalingv1.CrossVersionObjectReference `json:"ref,omitempty"`
+	// Selector identifies all resources of a kind in the namespace which match a label selector. Exactly one of Ref and Selector should be set.
+	// The level with which each selected resource is scaled up/down is read from annotations on the resource. If the annotations are not set
+	// then the levels defined in ScaleUpInfo and ScaleDownInfo are used.
+	Selector *ResourceSelector `json:"selector,omitempty"`
+	// Namespace is the namespace of the resource identified by Ref or of the resources selected via Selector. It is a Go template which
+	// refers to the shoot namespace via {{ .ShootNamespace }}, e.g. "{{ .ShootNamespace }}-extension", so that each shoot has its own namespace.
+	// If this field is not specified, then the shoot namespace is used.
+	Namespace string `json:"namespace,omitempty"`
 	// Optional should be false if this resource should be present. If the resource is optional then it should be true
 	// If this field is not specified, then its zero value (false for boolean) will be assumed.
+	// Resources identified via a Selector are always considered optional.
 	Optional bool `json:"optional"`
 	// ScaleUpInfo captures the configuration to scale up the resource identified by Ref
 	ScaleUpInfo *ScaleInfo `json:"scaleUp,omitempty"`
@@ -42,6 +110,16 @@ type DependentResourceInfo struct {
 	ScaleDownInfo *ScaleInfo `json:"scaleDown,omitempty"`
 }
 
+// ResourceSelector identifies a set of resources of the same kind using a label selector.
+type ResourceSelector struct {
+	// Kind is the kind of the selected resources.
+	Kind string `json:"kind"`
+	// APIVersion is the API version of the selected resources.
+	APIVersion string `json:"apiVersion"`
+	// LabelSelector is used to select resources within the namespace of the dependent resource.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector"`
+}
+
 // ScaleInfo captures the configuration required to scale a dependent resource
 type ScaleInfo struct {
 	// Level is used to order the dependent resources. Highest level or the first level starts at 0 and increments. Each dependent resource on a level will have to wait for
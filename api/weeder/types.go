@@ -15,6 +15,38 @@ type Config struct {
 	WatchDuration *metav1.Duration `json:"watchDuration,omitempty"`
 	// ServicesAndDependantSelectors is a map whose key is the service name and the value is a DependantSelectors
 	ServicesAndDependantSelectors map[string]DependantSelectors `json:"servicesAndDependantSelectors"`
+	// Dependants is a list of dependants which depend on several services at once. Unlike for ServicesAndDependantSelectors, where
+	// the dependant pods of a service are weeded as soon as the service is ready, the dependant pods are only weeded once all
+	// required services of the dependant are ready.
+	Dependants []Dependant `json:"dependants,omitempty"`
+	// NamespaceSelection restricts the namespaces in which the services are watched and their dependant pods are weeded.
+	// If not set then services in all namespaces are watched.
+	NamespaceSelection *NamespaceSelection `json:"namespaceSelection,omitempty"`
+	// Eviction configures the weeder to evict pods through the Eviction API, which honours PodDisruptionBudgets, instead of deleting them.
+	// If not set then pods are deleted.
+	Eviction *Eviction `json:"eviction,omitempty"`
+}
+
+// NamespaceSelection defines the namespaces in which the weeder is active. A namespace is selected if it is included, is not
+// excluded and its labels match the selector.
+type NamespaceSelection struct {
+	// Selector is a LabelSelector which the labels of a namespace must match. If not set then the labels are not considered.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Include is a list of namespace names. If set then only these namespaces are selected and only the objects in these
+	// namespaces are cached.
+	Include []string `json:"include,omitempty"`
+	// Exclude is a list of namespace names which are never selected.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Eviction defines how pods are evicted through the Eviction API.
+type Eviction struct {
+	// RetryInterval is the interval after which the eviction of a pod is retried if it has been rejected because it would violate a
+	// PodDisruptionBudget. Evictions are only retried as long as the weeder is running, which is the WatchDuration.
+	RetryInterval *metav1.Duration `json:"retryInterval,omitempty"`
+	// FallbackToDeleteAfter is the duration after which a pod is deleted if its eviction has been rejected for at least as long.
+	// If not set then a pod is never deleted if it cannot be evicted.
+	FallbackToDeleteAfter *metav1.Duration `json:"fallbackToDeleteAfter,omitempty"`
 }
 
 // DependantSelectors encapsulates LabelSelector's used to identify dependants for a service.
@@ -22,4 +54,76 @@ type Config struct {
 type DependantSelectors struct {
 	// PodSelectors is a slice of LabelSelector's used to identify dependant pods
 	PodSelectors []*metav1.LabelSelector `json:"podSelectors"`
+	// WeedableConditions defines the conditions under which a dependant pod is considered stuck and is deleted.
+	// If not set then a pod is deleted if any of its containers is in CrashLoopBackOff.
+	WeedableConditions *WeedableConditions `json:"weedableConditions,omitempty"`
+	// Throttling defines how the removal of the weedable dependant pods is throttled, so that they are restarted progressively.
+	// If not set then all weedable pods are removed immediately.
+	Throttling *Throttling `json:"throttling,omitempty"`
+	// Action is the action which is taken for a weedable dependant pod. If not specified its default value will be DeletePod.
+	Action *WeederAction `json:"action,omitempty"`
+	// DependantServices are the names of configured services which depend on this service. Once this service has recovered,
+	// the dependant pods of each dependant service are weeded as soon as the dependant service is ready, which cascades a
+	// recovery along a chain of services. The dependencies between the services must not contain a cycle.
+	DependantServices []string `json:"dependantServices,omitempty"`
+	// WatchDuration overrides the WatchDuration of the Config for the dependant pods of this service, e.g. for slow starting dependants.
+	// If not set then the WatchDuration of the Config is used.
+	WatchDuration *metav1.Duration `json:"watchDuration,omitempty"`
+}
+
+// Dependant identifies dependant pods which depend on several services at once.
+type Dependant struct {
+	// Name uniquely identifies the dependant. It is used to identify the weeder of the dependant.
+	Name string `json:"name"`
+	// RequiredServices are the names of the services on which the dependant pods depend. The dependant pods are only weeded once
+	// all of these services are ready.
+	RequiredServices []string `json:"requiredServices"`
+	// DependantSelectors identify the dependant pods and define how they are weeded. DependantServices are not supported for a dependant.
+	DependantSelectors `json:",inline"`
+}
+
+// WeederAction defines the action which is taken for a weedable dependant pod.
+type WeederAction string
+
+const (
+	// WeederActionDeletePod deletes the weedable pod, or evicts it if Eviction is configured.
+	WeederActionDeletePod WeederAction = "DeletePod"
+	// WeederActionRolloutRestart triggers a rollout restart of the Deployment, StatefulSet or DaemonSet owning the weedable pod,
+	// by updating an annotation of its pod template. Each workload is restarted at most once within the WatchDuration.
+	WeederActionRolloutRestart WeederAction = "RolloutRestart"
+)
+
+// Throttling defines how the removal of the weedable dependant pods of a service is throttled. Weedable pods are removed in
+// batches, in the configured priority order.
+type Throttling struct {
+	// MaxConcurrentRemovals is the maximum number of pods which are removed in a batch. The next batch is only removed once all
+	// pods of the previous batch are gone.
+	MaxConcurrentRemovals int `json:"maxConcurrentRemovals"`
+	// BatchDelay is the delay between the pods of a batch being gone and the removal of the next batch.
+	// If not set then the next batch is removed as soon as the pods of the previous batch are gone.
+	BatchDelay *metav1.Duration `json:"batchDelay,omitempty"`
+	// PriorityOrder is a list of LabelSelector's which defines the order in which weedable pods are removed. Pods matching an
+	// earlier selector are removed before pods matching a later one, pods which do not match any selector are removed last.
+	PriorityOrder []*metav1.LabelSelector `json:"priorityOrder,omitempty"`
+}
+
+// WeedableConditions defines the conditions under which a dependant pod is considered stuck. A pod is weeded if it
+// satisfies any of the conditions.
+type WeedableConditions struct {
+	// IncludeInitContainers defines if the statuses of the init containers of a pod are also considered when matching the WaitingReasons.
+	IncludeInitContainers bool `json:"includeInitContainers,omitempty"`
+	// WaitingReasons are the reasons for which a waiting container makes a pod weedable, e.g. CrashLoopBackOff or CreateContainerError.
+	// If not set then it defaults to CrashLoopBackOff.
+	WaitingReasons []string `json:"waitingReasons,omitempty"`
+	// MinRestartCount is the minimum number of restarts of a container before its waiting reason makes a pod weedable.
+	// If not set then a container with a matching waiting reason makes a pod weedable irrespective of its restarts.
+	MinRestartCount *int32 `json:"minRestartCount,omitempty"`
+	// MinWaitingDuration is the minimum duration for which a container must have been waiting for a matching reason before it makes
+	// a pod weedable. It is measured since the container has last terminated, or since the pod has started if the container has
+	// not terminated yet. This gives a container in CrashLoopBackOff the chance to recover with its next restart on its own.
+	// If not set then a container with a matching waiting reason makes a pod weedable right away.
+	MinWaitingDuration *metav1.Duration `json:"minWaitingDuration,omitempty"`
+	// NotReadyFor is the duration after which a pod which is not ready is considered weedable, irrespective of the state of its containers.
+	// This covers pods which are stuck with failing readiness probes. If not set then the readiness of a pod is not considered.
+	NotReadyFor *metav1.Duration `json:"notReadyFor,omitempty"`
 }
@@ -5,26 +5,41 @@
 package cmd
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"strings"
+	"time"
+
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/gardener/dependency-watchdog/controllers/cluster"
 	"github.com/gardener/dependency-watchdog/internal/prober"
+	"github.com/gardener/dependency-watchdog/internal/prober/scaler"
 	"github.com/gardener/dependency-watchdog/internal/util"
+	"github.com/gardener/dependency-watchdog/webhooks/scaleprotection"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 const (
 	proberLeaderElectionID = "dwd-prober-leader-election"
-	weederLeaderElectionID = "dwd-weeder-leader-election"
+	// proberEventRecorderName is the source component of the events which are recorded by the probers.
+	proberEventRecorderName = "dependency-watchdog-prober"
+	weederLeaderElectionID  = "dwd-weeder-leader-election"
+	defaultWebhookPort      = 9443
+	defaultWebhookCertDir   = "/etc/dependency-watchdog/webhook/certs"
 )
 
 var (
@@ -60,6 +75,20 @@ Flags:
 		TCP address that the controller should bind to for serving prometheus metrics
 	--health-bind-address
 		TCP address that the controller should bind to for serving health probes
+	--enable-scale-protection-webhook
+		Determines if the webhook which prevents other actors from scaling up resources held down by DWD should be served.
+	--webhook-port
+		Port on which the webhook server listens.
+	--webhook-cert-dir
+		Directory which contains the TLS certificate (tls.crt) and key (tls.key) for the webhook server.
+	--scale-protection-exempt-usernames
+		Comma separated list of usernames which are allowed to scale up resources held down by DWD in addition to DWD itself.
+	--max-concurrent-resource-updates
+		Maximum number of dependent resources whose replicas are updated concurrently across all shoots. Further updates are queued. 0 means unlimited. <optional>
+	--probe-workers
+		Number of workers which run the probes of all shoots. 0 means that each probe runs in its own goroutine. <optional>
+	--probe-startup-ramp
+		Duration over which the first probes are spread after the prober has started or has become the leader. 0 disables the ramp. <optional>
 `,
 		AddFlags: addProbeFlags,
 		Run:      startClusterControllerMgr,
@@ -70,6 +99,28 @@ Flags:
 
 type proberOptions struct {
 	SharedOpts
+	// ScaleProtectionWebhook defines the configuration of the scale protection webhook.
+	ScaleProtectionWebhook ScaleProtectionWebhookOpts
+	// MaxConcurrentResourceUpdates is the maximum number of dependent resources whose replicas are updated concurrently across all shoots. 0 means unlimited.
+	MaxConcurrentResourceUpdates int
+	// ProbeWorkers is the number of workers of the probe scheduler. 0 means that each probe runs in its own goroutine.
+	ProbeWorkers int
+	// ProbeStartupRamp is the duration over which the first probes are spread after the prober has started. 0 disables the ramp.
+	ProbeStartupRamp time.Duration
+}
+
+// ScaleProtectionWebhookOpts defines the configuration of the webhook which prevents other actors from
+// scaling up resources which are held down by DWD.
+type ScaleProtectionWebhookOpts struct {
+	// Enable enables serving the scale protection webhook. By default, it is false
+	Enable bool
+	// Port is the port on which the webhook server listens
+	Port int
+	// CertDir is the directory which contains the TLS certificate and key for the webhook server
+	CertDir string
+	// ExemptUsernames is a comma separated list of usernames which are allowed to scale up resources held down by DWD.
+	// DWD itself is always exempted.
+	ExemptUsernames string
 }
 
 func init() {
@@ -82,6 +133,13 @@ func init() {
 
 func addProbeFlags(fs *flag.FlagSet) {
 	SetSharedOpts(fs, &proberOpts.SharedOpts)
+	fs.BoolVar(&proberOpts.ScaleProtectionWebhook.Enable, "enable-scale-protection-webhook", false, "Serve the webhook which prevents other actors from scaling up resources held down by DWD")
+	fs.IntVar(&proberOpts.ScaleProtectionWebhook.Port, "webhook-port", defaultWebhookPort, "Port on which the webhook server listens")
+	fs.StringVar(&proberOpts.ScaleProtectionWebhook.CertDir, "webhook-cert-dir", defaultWebhookCertDir, "Directory which contains the TLS certificate (tls.crt) and key (tls.key) for the webhook server")
+	fs.StringVar(&proberOpts.ScaleProtectionWebhook.ExemptUsernames, "scale-protection-exempt-usernames", "", "Comma separated list of usernames which are allowed to scale up resources held down by DWD in addition to DWD itself")
+	fs.IntVar(&proberOpts.MaxConcurrentResourceUpdates, "max-concurrent-resource-updates", 0, "Maximum number of dependent resources whose replicas are updated concurrently across all shoots, further updates are queued. 0 means unlimited")
+	fs.IntVar(&proberOpts.ProbeWorkers, "probe-workers", 0, "Number of workers which run the probes of all shoots. 0 means that each probe runs in its own goroutine")
+	fs.DurationVar(&proberOpts.ProbeStartupRamp, "probe-startup-ramp", 0, "Duration over which the first probes are spread after the prober has started or has become the leader. 0 disables the ramp")
 }
 
 func startClusterControllerMgr(logger logr.Logger) (manager.Manager, error) {
@@ -95,8 +153,17 @@ func startClusterControllerMgr(logger logr.Logger) (manager.Manager, error) {
 	restConf.QPS = float32(proberOpts.KubeApiQps)
 	restConf.Burst = proberOpts.KubeApiBurst
 
+	var webhookServer webhook.Server
+	if proberOpts.ScaleProtectionWebhook.Enable {
+		webhookServer = webhook.NewServer(webhook.Options{
+			Port:    proberOpts.ScaleProtectionWebhook.Port,
+			CertDir: proberOpts.ScaleProtectionWebhook.CertDir,
+		})
+	}
+
 	mgr, err := ctrl.NewManager(restConf, ctrl.Options{
 		Scheme:                     scheme,
+		WebhookServer:              webhookServer,
 		Metrics:                    server.Options{BindAddress: proberOpts.SharedOpts.MetricsBindAddress},
 		HealthProbeBindAddress:     proberOpts.SharedOpts.HealthBindAddress,
 		LeaderElection:             proberOpts.SharedOpts.LeaderElection.Enable,
@@ -112,20 +179,88 @@ func startClusterControllerMgr(logger logr.Logger) (manager.Manager, error) {
 		return nil, fmt.Errorf("failed to start the prober controller manager %w", err)
 	}
 
-	scalesGetter, err := util.CreateScalesGetter(ctrl.GetConfigOrDie())
+	// the scale flows read and update the dependent resources with a client which bypasses the cache, so that a flow always sees its own writes.
+	// Only the check if any dependent resource is scaled down, which is done on every probe, is served from the cache of the manager.
+	liveClient, err := client.New(restConf, client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create live client for the scale flows %w", err)
+	}
+
+	scalesGetter, scaleKindResolver, err := util.CreateScalesGetter(ctrl.GetConfigOrDie())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create clientSet for scalesGetter %w", err)
 	}
+	if err = prober.ValidateScaleSubresources(proberConfig, mgr.GetRESTMapper(), scaleKindResolver); err != nil {
+		return nil, fmt.Errorf("dependent resources in prober config file %s cannot be scaled %w", proberOpts.ConfigFile, err)
+	}
+
+	var resourceUpdateLimiter *scaler.ResourceUpdateLimiter
+	if proberOpts.MaxConcurrentResourceUpdates > 0 {
+		resourceUpdateLimiter = scaler.NewResourceUpdateLimiter(proberOpts.MaxConcurrentResourceUpdates)
+	}
+
+	var probeScheduler *prober.Scheduler
+	if proberOpts.ProbeWorkers > 0 {
+		probeScheduler = prober.NewScheduler(proberOpts.ProbeWorkers, logger.WithName("probe-scheduler"))
+		if err = mgr.Add(probeScheduler); err != nil {
+			return nil, fmt.Errorf("failed to add the probe scheduler to the prober controller manager %w", err)
+		}
+	}
+
+	var probeStartupRamp *prober.StartupRamp
+	if proberOpts.ProbeStartupRamp > 0 {
+		probeStartupRamp = prober.NewStartupRamp(proberOpts.ProbeStartupRamp)
+	}
 
 	if err := (&cluster.Reconciler{
 		Client:                  mgr.GetClient(),
+		LiveClient:              liveClient,
 		Scheme:                  mgr.GetScheme(),
 		ScaleGetter:             scalesGetter,
-		ProberMgr:               prober.NewManager(),
+		ProberMgr:               prober.NewManager(prober.WithBlastRadiusGuard(proberConfig.BlastRadiusGuard, logger.WithName("blast-radius-guard"))),
 		DefaultProbeConfig:      proberConfig,
 		MaxConcurrentReconciles: proberOpts.ConcurrentReconciles,
+		ResourceUpdateLimiter:   resourceUpdateLimiter,
+		ProbeScheduler:          probeScheduler,
+		ProbeStartupRamp:        probeStartupRamp,
+		EventRecorder:           mgr.GetEventRecorderFor(proberEventRecorderName),
 	}).SetupWithManager(mgr); err != nil {
 		return nil, fmt.Errorf("failed to register cluster reconciler with the prober controller manager %w", err)
 	}
+
+	if proberOpts.ScaleProtectionWebhook.Enable {
+		if err := addScaleProtectionWebhook(mgr, restConf, logger.WithName("scale-protection-webhook")); err != nil {
+			return nil, fmt.Errorf("failed to register scale protection webhook with the prober controller manager %w", err)
+		}
+	}
 	return mgr, nil
 }
+
+// addScaleProtectionWebhook registers the scale protection webhook. DWD has to be exempted from the scale protection as it
+// scales up the resources it holds down, therefore the username with which DWD is authenticated is determined upfront.
+func addScaleProtectionWebhook(mgr manager.Manager, restConf *rest.Config, logger logr.Logger) error {
+	var exemptUsernames []string
+	for _, username := range strings.Split(proberOpts.ScaleProtectionWebhook.ExemptUsernames, ",") {
+		if username = strings.TrimSpace(username); username != "" {
+			exemptUsernames = append(exemptUsernames, username)
+		}
+	}
+	dwdUsername, err := util.GetAuthenticatedUsername(context.Background(), restConf)
+	if err != nil {
+		if len(exemptUsernames) == 0 {
+			return fmt.Errorf("failed to determine the username of DWD, it has to be passed via --scale-protection-exempt-usernames: %w", err)
+		}
+		logger.Error(err, "Failed to determine the username of DWD, only the configured usernames are exempted from scale protection", "exemptUsernames", exemptUsernames)
+	} else {
+		exemptUsernames = append(exemptUsernames, dwdUsername)
+	}
+	logger.Info("Registering scale protection webhook", "path", scaleprotection.WebhookPath, "exemptUsernames", exemptUsernames)
+	(&scaleprotection.Handler{
+		Client:          mgr.GetAPIReader(),
+		RESTMapper:      mgr.GetRESTMapper(),
+		Decoder:         admission.NewDecoder(mgr.GetScheme()),
+		ExemptUsernames: sets.New[string](exemptUsernames...),
+		Logger:          logger,
+	}).AddToManager(mgr)
+	return nil
+}
@@ -9,8 +9,8 @@ import (
 	"fmt"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
 	"github.com/gardener/dependency-watchdog/controllers/endpoint"
-	internalutils "github.com/gardener/dependency-watchdog/internal/util"
 	"github.com/gardener/dependency-watchdog/internal/weeder"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -53,6 +53,8 @@ Flags:
 		TCP address that the controller should bind to for serving prometheus metrics
 	--health-bind-address
 		TCP address that the controller should bind to for serving health probes
+	--endpoint-source
+		Source from which the readiness of a service is determined. Either Endpoints or EndpointSlices. Defaults to Endpoints. <optional>
 `,
 		AddFlags: addWeederFlags,
 		Run:      startEndpointsControllerMgr,
@@ -60,12 +62,22 @@ Flags:
 	weederOpts = weederOptions{}
 )
 
+const (
+	// endpointSourceEndpoints determines the readiness of a service from its v1.Endpoints.
+	endpointSourceEndpoints = "Endpoints"
+	// endpointSourceEndpointSlices determines the readiness of a service from all its discovery.k8s.io/v1 EndpointSlices.
+	endpointSourceEndpointSlices = "EndpointSlices"
+)
+
 type weederOptions struct {
 	SharedOpts
+	// EndpointSource is the source from which the readiness of a service is determined. Either Endpoints or EndpointSlices.
+	EndpointSource string
 }
 
 func addWeederFlags(fs *flag.FlagSet) {
 	SetSharedOpts(fs, &weederOpts.SharedOpts)
+	fs.StringVar(&weederOpts.EndpointSource, "endpoint-source", endpointSourceEndpoints, fmt.Sprintf("Source from which the readiness of a service is determined. Either %s or %s", endpointSourceEndpoints, endpointSourceEndpointSlices))
 }
 
 func startEndpointsControllerMgr(logger logr.Logger) (manager.Manager, error) {
@@ -90,24 +102,39 @@ func startEndpointsControllerMgr(logger logr.Logger) (manager.Manager, error) {
 		LeaderElectionResourceLock: resourcelock.LeasesResourceLock,
 		LeaderElectionID:           weederLeaderElectionID,
 		Logger:                     weederLogger,
+		Cache:                      weeder.GetCacheOptions(weederConfig),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to start the weeder controller manager %w", err)
 	}
 
-	// create clientSet
-	clientSet, err := internalutils.CreateClientSetFromRestConfig(restConf)
-	if err != nil {
-		return nil, fmt.Errorf("failed creating clientset for dwd-weeder %w", err)
+	if err := setupWeederReconciler(mgr, weederConfig); err != nil {
+		return nil, err
 	}
+	return mgr, nil
+}
 
-	if err := (&endpoint.Reconciler{
-		Client:       mgr.GetClient(),
-		SeedClient:   clientSet,
-		WeederConfig: weederConfig,
-		WeederMgr:    weeder.NewManager(),
-	}).SetupWithManager(mgr); err != nil {
-		return nil, fmt.Errorf("failed to register endpoint reconciler with weeder controller manager %w", err)
+// setupWeederReconciler registers the reconciler for the configured endpoint source with the weeder controller manager.
+func setupWeederReconciler(mgr manager.Manager, weederConfig *wapi.Config) error {
+	switch weederOpts.EndpointSource {
+	case endpointSourceEndpoints:
+		if err := (&endpoint.Reconciler{
+			Client:       mgr.GetClient(),
+			WeederConfig: weederConfig,
+			WeederMgr:    weeder.NewManager(),
+		}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("failed to register endpoint reconciler with weeder controller manager %w", err)
+		}
+	case endpointSourceEndpointSlices:
+		if err := (&endpoint.EndpointSliceReconciler{
+			Client:       mgr.GetClient(),
+			WeederConfig: weederConfig,
+			WeederMgr:    weeder.NewManager(),
+		}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("failed to register endpointslice reconciler with weeder controller manager %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid endpoint source %s, must be one of %s, %s", weederOpts.EndpointSource, endpointSourceEndpoints, endpointSourceEndpointSlices)
 	}
-	return mgr, nil
+	return nil
 }
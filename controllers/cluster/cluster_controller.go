@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/scale"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -39,6 +40,9 @@ type Reconciler struct {
 	Scheme *runtime.Scheme
 	// ProberMgr is interface to manage lifecycle of probers.
 	ProberMgr prober.Manager
+	// LiveClient if set, is used by the scale flows to read and update the dependent resources without going through the cache of the
+	// controller manager, which ensures that a flow always sees its own writes. The embedded Client is used otherwise.
+	LiveClient client.Client
 	// ScaleGetter is used to produce a ScaleInterface
 	ScaleGetter scale.ScalesGetter
 	// DefaultProbeConfig is the seed level config inherited by all shoots whose control plane is hosted in the seed. The default config is used
@@ -47,10 +51,23 @@ type Reconciler struct {
 	DefaultProbeConfig *papi.Config
 	// MaxConcurrentReconciles is the maximum number of concurrent Reconciles which can be run. Defaults to 1.
 	MaxConcurrentReconciles int
+	// ResourceUpdateLimiter if set, limits the number of dependent resources whose replicas are updated concurrently across all shoots. It is shared by all scalers.
+	ResourceUpdateLimiter *scaler.ResourceUpdateLimiter
+	// ProbeScheduler if set, runs the probes of all probers with a bounded pool of workers. Otherwise, each prober runs in its own goroutine.
+	ProbeScheduler *prober.Scheduler
+	// EventRecorder if set, is used by the probers to record events on the Cluster of their shoot.
+	EventRecorder record.EventRecorder
+	// ProbeStartupRamp if set, spreads the first probes of the probers which are started after the controller has started over the ramp.
+	ProbeStartupRamp *prober.StartupRamp
 }
 
 //+kubebuilder:rbac:groups=gardener.cloud,resources=clusters,verbs=get;list;watch
 //+kubebuilder:rbac:groups=gardener.cloud,resources=clusters/status,verbs=get
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=apps,resources=deployments/scale;statefulsets/scale,verbs=get;update
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=selfsubjectreviews,verbs=create
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile listens to create/update/delete events for `Cluster` resources and
 // manages probes for the shoot control namespace for these clusters by looking at the cluster state.
@@ -109,7 +126,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	if canStartProber(shoot) {
-		r.startProber(ctx, shoot, log, req.Name)
+		if err = r.startProber(ctx, cluster, shoot, log, req.Name); err != nil {
+			return ctrl.Result{}, err
+		}
 	}
 	return ctrl.Result{}, nil
 }
@@ -146,18 +165,36 @@ func canStartProber(shoot *v1beta1.Shoot) bool {
 }
 
 // startProber sets up a new probe against a given key which uniquely identifies the probe.
-// Typically, the key in case of a shoot cluster is the shoot namespace
-func (r *Reconciler) startProber(ctx context.Context, shoot *v1beta1.Shoot, logger logr.Logger, key string) {
+// Typically, the key in case of a shoot cluster is the shoot namespace. An error is returned if the prober cannot be registered because
+// a namespace of its dependent resources is already claimed by the prober of another shoot, the reconcile is then retried.
+func (r *Reconciler) startProber(ctx context.Context, cluster *extensionsv1alpha1.Cluster, shoot *v1beta1.Shoot, logger logr.Logger, key string) error {
 	_, ok := r.ProberMgr.GetProber(key)
 	if !ok {
 		probeConfig := r.getEffectiveProbeConfig(shoot, logger)
-		deploymentScaler := scaler.NewScaler(key, probeConfig.DependentResourceInfos, r.Client, r.ScaleGetter, logger)
+		deploymentScaler := scaler.NewScaler(key, probeConfig.DependentResourceInfos, r.getLiveClient(), r.ScaleGetter, logger,
+			scaler.WithScaleHooks(probeConfig.ScaleHooks), scaler.WithResourceUpdateLimiter(r.ResourceUpdateLimiter), scaler.WithCachedReader(r.Client))
 		shootClientCreator := prober.NewShootClientCreator(r.Client)
-		p := prober.NewProber(ctx, key, probeConfig, deploymentScaler, shootClientCreator, logger)
-		r.ProberMgr.Register(*p)
+		p := prober.NewProber(ctx, key, probeConfig, deploymentScaler, shootClientCreator, logger, prober.WithScaleDownGuard(r.ProberMgr.GetScaleDownGuard()),
+			prober.WithStartupRamp(r.ProbeStartupRamp), prober.WithEventRecorder(r.EventRecorder, cluster))
+		if !r.ProberMgr.Register(*p) {
+			p.Close()
+			return fmt.Errorf("failed to register prober for %s, a namespace of its dependent resources is already claimed by the prober of another shoot, shared namespaces are not supported", key)
+		}
 		logger.Info("Starting a new prober")
+		if r.ProbeScheduler != nil {
+			r.ProbeScheduler.Schedule(p)
+			return nil
+		}
 		go p.Run()
 	}
+	return nil
+}
+
+func (r *Reconciler) getLiveClient() client.Client {
+	if r.LiveClient != nil {
+		return r.LiveClient
+	}
+	return r.Client
 }
 
 // SetupWithManager sets up the controller with the Manager.
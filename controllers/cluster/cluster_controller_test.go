@@ -73,7 +73,7 @@ func setupProberEnv(ctx context.Context, g *WithT) (client.Client, *envtest.Envi
 	})
 	g.Expect(err).ToNot(HaveOccurred())
 
-	scalesGetter, err := util.CreateScalesGetter(cfg)
+	scalesGetter, _, err := util.CreateScalesGetter(cfg)
 	g.Expect(err).ToNot(HaveOccurred())
 
 	probeConfigPath := filepath.Join(testdataPath, "prober-config.yaml")
@@ -5,10 +5,15 @@
 package endpoint
 
 import (
-	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"context"
+
+	"github.com/gardener/dependency-watchdog/internal/weeder"
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
@@ -22,10 +27,8 @@ func ReadyEndpoints(logger logr.Logger) predicate.Predicate {
 		if !ok || ep == nil {
 			return false
 		}
-		for _, subset := range ep.Subsets {
-			if len(subset.Addresses) > 0 {
-				return true
-			}
+		if isEndpointsReady(ep) {
+			return true
 		}
 		log.Info("Endpoint does not have any IP address. Skipping processing this endpoint", "namespace", ep.Namespace, "endpoint", ep.Name)
 		return false
@@ -57,24 +60,33 @@ func ReadyEndpoints(logger logr.Logger) predicate.Predicate {
 	}
 }
 
-// MatchingEndpoints is a predicate to allow events for only configured endpoints
-func MatchingEndpoints(epMap map[string]wapi.DependantSelectors) predicate.Predicate {
-	isMatchingEndpoints := func(obj runtime.Object, epMap map[string]wapi.DependantSelectors) bool {
+// isEndpointsReady checks if there is at least a single endpoint subset that has at least one IP address assigned.
+func isEndpointsReady(ep *v1.Endpoints) bool {
+	for _, subset := range ep.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchingEndpoints is a predicate to allow events for only endpoints of the given services
+func MatchingEndpoints(serviceNames sets.Set[string]) predicate.Predicate {
+	isMatchingEndpoints := func(obj runtime.Object, serviceNames sets.Set[string]) bool {
 		ep, ok := obj.(*v1.Endpoints)
 		if !ok || ep == nil {
 			return false
 		}
-		_, exists := epMap[ep.Name]
-		return exists
+		return serviceNames.Has(ep.Name)
 	}
 
 	return predicate.Funcs{
 		CreateFunc: func(event event.CreateEvent) bool {
-			return isMatchingEndpoints(event.Object, epMap)
+			return isMatchingEndpoints(event.Object, serviceNames)
 		},
 
 		UpdateFunc: func(event event.UpdateEvent) bool {
-			return isMatchingEndpoints(event.ObjectNew, epMap)
+			return isMatchingEndpoints(event.ObjectNew, serviceNames)
 		},
 
 		DeleteFunc: func(event event.DeleteEvent) bool {
@@ -82,7 +94,33 @@ func MatchingEndpoints(epMap map[string]wapi.DependantSelectors) predicate.Predi
 		},
 
 		GenericFunc: func(event event.GenericEvent) bool {
-			return isMatchingEndpoints(event.Object, epMap)
+			return isMatchingEndpoints(event.Object, serviceNames)
 		},
 	}
 }
+
+// MatchingEndpointSlices is a predicate to allow events for only EndpointSlices of the given services. Unlike for Endpoints,
+// delete events are allowed, as the deletion of an EndpointSlice can change the readiness of its service.
+func MatchingEndpointSlices(serviceNames sets.Set[string]) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		endpointSlice, ok := obj.(*discoveryv1.EndpointSlice)
+		if !ok || endpointSlice == nil {
+			return false
+		}
+		return serviceNames.Has(endpointSlice.Labels[discoveryv1.LabelServiceName])
+	})
+}
+
+// MatchingNamespaces is a predicate to allow events for only objects in namespaces which are selected by the namespace matcher.
+// The labels of a namespace are read via the given reader, which is expected to be backed by a cache.
+func MatchingNamespaces(reader client.Reader, matcher weeder.NamespaceMatcher, logger logr.Logger) predicate.Predicate {
+	log := logger.WithValues("predicate", "MatchingNamespacesPredicate")
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		matches, err := matcher.Matches(context.Background(), reader, obj.GetNamespace())
+		if err != nil {
+			log.Error(err, "Failed to check if namespace is selected. Skipping processing this object", "namespace", obj.GetNamespace(), "name", obj.GetName())
+			return false
+		}
+		return matches
+	})
+}
@@ -9,11 +9,11 @@ package endpoint
 import (
 	"testing"
 
-	v12 "github.com/gardener/dependency-watchdog/api/weeder"
 	"github.com/go-logr/logr"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 )
@@ -130,11 +130,7 @@ func TestReadyEndpoints(t *testing.T) {
 func TestMatchingEndpointsPredicate(t *testing.T) {
 	g := NewWithT(t)
 
-	epMap := map[string]v12.DependantSelectors{
-		"ep-relevant": {},
-	}
-
-	predicate := MatchingEndpoints(epMap)
+	predicate := MatchingEndpoints(sets.New("ep-relevant"))
 
 	epRelevant := &v1.Endpoints{
 		ObjectMeta: metav1.ObjectMeta{
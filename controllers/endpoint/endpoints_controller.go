@@ -6,14 +6,17 @@ package endpoint
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"time"
 
 	wapi "github.com/gardener/dependency-watchdog/api/weeder"
 	"github.com/gardener/dependency-watchdog/internal/weeder"
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/kubernetes"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -27,14 +30,19 @@ const controllerName = "endpoint"
 // Reconciler EndpointReconciler reconciles an Endpoints object
 type Reconciler struct {
 	client.Client
-	SeedClient              kubernetes.Interface
 	WeederConfig            *wapi.Config
 	WeederMgr               weeder.Manager
 	MaxConcurrentReconciles int
+	// podInformer is the shared informer for pods from the cache of the manager. It is used by all weeders to watch the dependant pods.
+	podInformer cache.Informer
 }
 
 // +kubebuilder:rbac:resources=endpoints,verbs=get;list;watch
 // +kubebuilder:rbac:resources=pods,verbs=get;list;watch;delete
+// +kubebuilder:rbac:resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:resources=pods/eviction,verbs=create
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=patch
 
 // Reconcile listens to create/update events for `Endpoints` resources and manages weeder which shoot the dependent pods of the configured services, if necessary
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -45,21 +53,89 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err != nil {
 		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
 	}
-	log.Info("Starting a new weeder for endpoint, replacing old weeder, if any exists", "namespace", req.Namespace, "endpoint", ep.Name)
-	r.startWeeder(ctx, log, req.Namespace, &ep)
+	if _, ok := r.WeederConfig.ServicesAndDependantSelectors[req.Name]; ok {
+		log.Info("Starting a new weeder for endpoint, replacing old weeder, if any exists", "namespace", req.Namespace, "endpoint", ep.Name)
+		startWeeder(ctx, log, req.NamespacedName, r.WeederConfig, r.Client, r.podInformer, r.WeederMgr)
+	}
+	startDependantWeeders(ctx, log, req.NamespacedName, r.WeederConfig, r.Client, r.podInformer, r.WeederMgr, r.isServiceReady)
 	return ctrl.Result{}, nil
 }
 
-// startWeeder starts a new weeder for the endpoint
-func (r *Reconciler) startWeeder(ctx context.Context, logger logr.Logger, namespace string, ep *v1.Endpoints) {
-	w := weeder.NewWeeder(ctx, namespace, r.WeederConfig, r.Client, r.SeedClient, ep, logger)
+// isServiceReady checks if the Endpoints of the service have at least one IP address assigned.
+func (r *Reconciler) isServiceReady(ctx context.Context, service types.NamespacedName) (bool, error) {
+	var ep v1.Endpoints
+	if err := r.Client.Get(ctx, service, &ep); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return isEndpointsReady(&ep), nil
+}
+
+// serviceReadinessFunc checks if a service has a ready endpoint. It must not have any side effect.
+type serviceReadinessFunc func(ctx context.Context, service types.NamespacedName) (bool, error)
+
+// startWeeder starts a new weeder for the service and registers it with the weeder manager, which replaces an existing weeder for the service.
+// The recovery of the service cascades to its dependant services via the dependency chain which the weeder manager records with the
+// registration. A dependant service gets its weeder only on its own ready transition, so that each hop is timed by the readiness of its
+// own service. Its current readiness is not checked here, as it might still be the readiness from before the upstream service has failed.
+func startWeeder(ctx context.Context, logger logr.Logger, service types.NamespacedName, config *wapi.Config, ctrlClient client.Client, podInformer cache.Informer, weederMgr weeder.Manager) {
+	chain := weederMgr.GetDependencyChain(service)
+	w := weeder.NewWeeder(ctx, service, config, ctrlClient, podInformer, logger, weeder.WithDependencyChain(chain))
 	// Register the weeder
-	r.WeederMgr.Register(*w)
+	weederMgr.Register(*w)
 	go w.Run()
+	if dependantServices := config.ServicesAndDependantSelectors[service.Name].DependantServices; len(dependantServices) > 0 {
+		logger.Info("Dependant services will get a weeder once they turn ready", "namespace", service.Namespace, "dependantServices", strings.Join(dependantServices, ","), "dependencyChain", strings.Join(chain, " -> "))
+	}
+}
+
+// startDependantWeeders starts a new weeder for each dependant which requires the service, once all its required services are ready.
+// A dependant whose other required services are not yet ready gets its weeder once the last of them turns ready.
+func startDependantWeeders(ctx context.Context, logger logr.Logger, service types.NamespacedName, config *wapi.Config, ctrlClient client.Client, podInformer cache.Informer, weederMgr weeder.Manager, isServiceReady serviceReadinessFunc) {
+	for _, dependant := range config.Dependants {
+		if !slices.Contains(dependant.RequiredServices, service.Name) {
+			continue
+		}
+		notReadyServices, err := getNotReadyServices(ctx, service, dependant.RequiredServices, isServiceReady)
+		if err != nil {
+			logger.Error(err, "Failed to check readiness of required services of dependant", "namespace", service.Namespace, "dependant", dependant.Name)
+			continue
+		}
+		if len(notReadyServices) > 0 {
+			logger.Info("Not all required services of dependant are ready yet, a weeder will be started once they are ready", "namespace", service.Namespace, "dependant", dependant.Name, "notReadyServices", strings.Join(notReadyServices, ","))
+			continue
+		}
+		logger.Info("Starting a new weeder for dependant as all its required services are ready, replacing old weeder, if any exists", "namespace", service.Namespace, "dependant", dependant.Name)
+		w := weeder.NewDependantWeeder(ctx, service, dependant, config, ctrlClient, podInformer, logger)
+		weederMgr.Register(*w)
+		go w.Run()
+	}
+}
+
+// getNotReadyServices returns the names of the required services which are not ready. The given service is known to be ready.
+func getNotReadyServices(ctx context.Context, service types.NamespacedName, requiredServices []string, isServiceReady serviceReadinessFunc) ([]string, error) {
+	var notReadyServices []string
+	for _, requiredServiceName := range requiredServices {
+		if requiredServiceName == service.Name {
+			continue
+		}
+		ready, err := isServiceReady(ctx, types.NamespacedName{Namespace: service.Namespace, Name: requiredServiceName})
+		if err != nil {
+			return nil, err
+		}
+		if !ready {
+			notReadyServices = append(notReadyServices, requiredServiceName)
+		}
+	}
+	return notReadyServices, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	podInformer, err := mgr.GetCache().GetInformer(context.Background(), &v1.Pod{})
+	if err != nil {
+		return err
+	}
+	r.podInformer = podInformer
 	c, err := controller.New(
 		controllerName,
 		mgr,
@@ -75,7 +151,8 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		&handler.EnqueueRequestForObject{},
 		predicate.And(
 			predicate.ResourceVersionChangedPredicate{},
-			MatchingEndpoints(r.WeederConfig.ServicesAndDependantSelectors),
+			MatchingEndpoints(weeder.GetWatchedServices(r.WeederConfig)),
+			MatchingNamespaces(mgr.GetClient(), weeder.NewNamespaceMatcher(r.WeederConfig.NamespaceSelection), c.GetLogger()),
 			ReadyEndpoints(c.GetLogger()),
 		),
 	)
@@ -16,7 +16,6 @@ import (
 	testutil "github.com/gardener/dependency-watchdog/internal/test"
 	"k8s.io/client-go/kubernetes/scheme"
 
-	internalutils "github.com/gardener/dependency-watchdog/internal/util"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -61,9 +60,6 @@ func setupWeederEnv(ctx context.Context, t *testing.T, kubeApiServerFlags map[st
 	cfg := controllerTestEnv.GetConfig()
 	crClient := controllerTestEnv.GetClient()
 
-	clientSet, err := internalutils.CreateClientSetFromRestConfig(cfg)
-	g.Expect(err).NotTo(HaveOccurred())
-
 	weederConfigPath := filepath.Join(testdataPath, "weeder-config.yaml")
 	testutil.ValidateIfFileExists(weederConfigPath, t)
 	weederConfig, err := weederpackage.LoadConfig(weederConfigPath)
@@ -72,7 +68,6 @@ func setupWeederEnv(ctx context.Context, t *testing.T, kubeApiServerFlags map[st
 	epReconciler := &Reconciler{
 		Client:                  crClient,
 		WeederConfig:            weederConfig,
-		SeedClient:              clientSet,
 		WeederMgr:               weederpackage.NewManager(),
 		MaxConcurrentReconciles: maxConcurrentReconcilesWeeder,
 	}
@@ -179,7 +174,8 @@ func testOnlyCLBFPodDeletion(ctx context.Context, _ context.CancelFunc, g *WithT
 	g.Expect(err).ToNot(HaveOccurred())
 	turnPodToCrashLoop(ctx, g, reconciler.Client, pC)
 
-	pl, err := reconciler.SeedClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	pl := &v1.PodList{}
+	err = reconciler.Client.List(ctx, pl, client.InNamespace(namespace))
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(pl.Items).Should(HaveLen(2))
 
@@ -204,7 +200,8 @@ func testPodTurningCLBFDeletion(ctx context.Context, _ context.CancelFunc, g *Wi
 	g.Expect(err).ToNot(HaveOccurred())
 	turnPodToHealthy(ctx, g, reconciler.Client, pod)
 
-	pl, err := reconciler.SeedClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	pl := &v1.PodList{}
+	err = reconciler.Client.List(ctx, pl, client.InNamespace(namespace))
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(pl.Items).Should(HaveLen(1))
 
@@ -285,7 +282,8 @@ func testNoCLBFPodDeletionOnContextCancellation(ctx context.Context, cancelFn co
 	// cancel context (like SIGKILL signal to the process)
 	cancelFn()
 
-	currentPod, err := reconciler.SeedClient.CoreV1().Pods(namespace).Get(context.TODO(), crashingPod, metav1.GetOptions{})
+	currentPod := &v1.Pod{}
+	err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: crashingPod}, currentPod)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(currentPod.DeletionTimestamp).To(BeNil())
 }
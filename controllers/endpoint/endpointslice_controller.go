@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package endpoint
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/gardener/dependency-watchdog/internal/weeder"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const endpointSliceControllerName = "endpointslice"
+
+// EndpointSliceReconciler reconciles the EndpointSlices of the configured services. A service can have several EndpointSlices,
+// therefore the reconcile requests are made for the service, and a service is considered ready if any of its EndpointSlices
+// has a ready endpoint. A weeder is started each time a service turns ready.
+type EndpointSliceReconciler struct {
+	client.Client
+	WeederConfig            *wapi.Config
+	WeederMgr               weeder.Manager
+	MaxConcurrentReconciles int
+	// podInformer is the shared informer for pods from the cache of the manager. It is used by all weeders to watch the dependant pods.
+	podInformer cache.Informer
+	mu          sync.Mutex
+	// readyServices are the services which have had a ready endpoint when they have last been reconciled.
+	readyServices sets.Set[types.NamespacedName]
+}
+
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
+
+// Reconcile listens to create/update/delete events for the `EndpointSlices` of the configured services and starts a weeder
+// which shoots the dependent pods of a service, if necessary, once the service has turned ready.
+func (r *EndpointSliceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	var endpointSlices discoveryv1.EndpointSliceList
+	if err := r.Client.List(ctx, &endpointSlices, client.InNamespace(req.Namespace), client.MatchingLabels{discoveryv1.LabelServiceName: req.Name}); err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+	}
+	ready := hasReadyEndpoint(endpointSlices.Items)
+	if !r.updateServiceReadiness(req.NamespacedName, ready) {
+		if !ready {
+			log.Info("Service does not have any ready endpoint in its EndpointSlices. Skipping processing this service", "namespace", req.Namespace, "service", req.Name)
+		}
+		return ctrl.Result{}, nil
+	}
+	if _, ok := r.WeederConfig.ServicesAndDependantSelectors[req.Name]; ok {
+		log.Info("Starting a new weeder for service, replacing old weeder, if any exists", "namespace", req.Namespace, "service", req.Name)
+		startWeeder(ctx, log, req.NamespacedName, r.WeederConfig, r.Client, r.podInformer, r.WeederMgr)
+	}
+	startDependantWeeders(ctx, log, req.NamespacedName, r.WeederConfig, r.Client, r.podInformer, r.WeederMgr, r.isServiceReady)
+	return ctrl.Result{}, nil
+}
+
+// isServiceReady checks if any of the EndpointSlices of the service has a ready endpoint. The readiness is not recorded, as a
+// service whose readiness is only checked, e.g. as it is a required service of a dependant, must still get its weeder once it is
+// reconciled itself.
+func (r *EndpointSliceReconciler) isServiceReady(ctx context.Context, service types.NamespacedName) (bool, error) {
+	var endpointSlices discoveryv1.EndpointSliceList
+	if err := r.Client.List(ctx, &endpointSlices, client.InNamespace(service.Namespace), client.MatchingLabels{discoveryv1.LabelServiceName: service.Name}); err != nil {
+		return false, err
+	}
+	return hasReadyEndpoint(endpointSlices.Items), nil
+}
+
+// updateServiceReadiness records the readiness of the service and returns true if the service has turned ready.
+func (r *EndpointSliceReconciler) updateServiceReadiness(service types.NamespacedName, ready bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.readyServices == nil {
+		r.readyServices = sets.New[types.NamespacedName]()
+	}
+	if !ready {
+		r.readyServices.Delete(service)
+		return false
+	}
+	if r.readyServices.Has(service) {
+		return false
+	}
+	r.readyServices.Insert(service)
+	return true
+}
+
+// hasReadyEndpoint checks if any of the given EndpointSlices has a ready endpoint. As defined by the EndpointSlice API, an
+// endpoint whose ready condition is not set is considered ready.
+func hasReadyEndpoint(endpointSlices []discoveryv1.EndpointSlice) bool {
+	for _, endpointSlice := range endpointSlices {
+		for _, ep := range endpointSlice.Endpoints {
+			if len(ep.Addresses) > 0 && (ep.Conditions.Ready == nil || *ep.Conditions.Ready) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mapEndpointSliceToService maps an EndpointSlice to a reconcile request for the service which it belongs to.
+func mapEndpointSliceToService(_ context.Context, obj client.Object) []reconcile.Request {
+	serviceName, ok := obj.GetLabels()[discoveryv1.LabelServiceName]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: serviceName}}}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *EndpointSliceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	podInformer, err := mgr.GetCache().GetInformer(context.Background(), &v1.Pod{})
+	if err != nil {
+		return err
+	}
+	r.podInformer = podInformer
+	c, err := controller.New(
+		endpointSliceControllerName,
+		mgr,
+		controller.Options{
+			MaxConcurrentReconciles: r.MaxConcurrentReconciles,
+			Reconciler:              r},
+	)
+	if err != nil {
+		return err
+	}
+	return c.Watch(
+		source.Kind(mgr.GetCache(), &discoveryv1.EndpointSlice{}),
+		handler.EnqueueRequestsFromMapFunc(mapEndpointSliceToService),
+		predicate.And(
+			predicate.ResourceVersionChangedPredicate{},
+			MatchingEndpointSlices(weeder.GetWatchedServices(r.WeederConfig)),
+			MatchingNamespaces(mgr.GetClient(), weeder.NewNamespaceMatcher(r.WeederConfig.NamespaceSelection), c.GetLogger()),
+		),
+	)
+}
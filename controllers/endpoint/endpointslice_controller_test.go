@@ -0,0 +1,223 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package endpoint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	mockclient "github.com/gardener/dependency-watchdog/internal/mock/controller-runtime/client"
+	"github.com/gardener/dependency-watchdog/internal/weeder"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestHasReadyEndpoint(t *testing.T) {
+	testcases := []struct {
+		name           string
+		endpointSlices []discoveryv1.EndpointSlice
+		expectedReady  bool
+	}{
+		{"no endpoint slices", nil, false},
+		{"only not ready endpoints", []discoveryv1.EndpointSlice{createEndpointSlice("slice-a", pointer.Bool(false)), createEndpointSlice("slice-b", pointer.Bool(false))}, false},
+		{"ready endpoint in one of the slices", []discoveryv1.EndpointSlice{createEndpointSlice("slice-a", pointer.Bool(false)), createEndpointSlice("slice-b", pointer.Bool(true))}, true},
+		{"endpoint without ready condition", []discoveryv1.EndpointSlice{createEndpointSlice("slice-a", nil)}, true},
+		{"endpoint slice without endpoints", []discoveryv1.EndpointSlice{{ObjectMeta: metav1.ObjectMeta{Name: "slice-a"}}}, false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(hasReadyEndpoint(tc.endpointSlices)).To(Equal(tc.expectedReady))
+		})
+	}
+}
+
+func TestServiceShouldOnlyBeReportedOnceWhenTurningReady(t *testing.T) {
+	g := NewWithT(t)
+	r := &EndpointSliceReconciler{}
+	service := types.NamespacedName{Namespace: "shoot--project--name", Name: epName}
+
+	g.Expect(r.updateServiceReadiness(service, false)).To(BeFalse())
+	g.Expect(r.updateServiceReadiness(service, true)).To(BeTrue(), "a service should be reported when turning ready")
+	g.Expect(r.updateServiceReadiness(service, true)).To(BeFalse(), "a service should not be reported again while it stays ready")
+	g.Expect(r.updateServiceReadiness(types.NamespacedName{Namespace: "other", Name: epName}, true)).To(BeTrue(), "services in different namespaces should be tracked separately")
+	g.Expect(r.updateServiceReadiness(service, false)).To(BeFalse())
+	g.Expect(r.updateServiceReadiness(service, true)).To(BeTrue(), "a service should be reported again after it has turned not ready")
+}
+
+func TestServiceReadinessShouldOnlyBeRecordedWhenItsWeederIsStarted(t *testing.T) {
+	const (
+		namespace     = "shoot--project--name"
+		kubeAPIServer = "kube-apiserver"
+	)
+	g := NewWithT(t)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	weederMgr := weeder.NewManager()
+	defer weederMgr.UnregisterAll()
+	r := &EndpointSliceReconciler{
+		Client: createReadyServicesClient(t, sets.New(epName, kubeAPIServer)),
+		WeederConfig: &wapi.Config{
+			WatchDuration: &metav1.Duration{Duration: time.Minute},
+			ServicesAndDependantSelectors: map[string]wapi.DependantSelectors{
+				kubeAPIServer: {},
+			},
+			Dependants: []wapi.Dependant{{Name: "dependant", RequiredServices: []string{epName, kubeAPIServer}}},
+		},
+		WeederMgr: weederMgr,
+	}
+	reconcileService := func(name string) {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+
+	// the readiness of kube-apiserver is only checked as it is a required service of the dependant.
+	reconcileService(epName)
+	_, ok := weederMgr.GetWeederRegistration(namespace + "/dependant/dependant")
+	g.Expect(ok).To(BeTrue(), "the weeder of the dependant should be started once all its required services are ready")
+	_, ok = weederMgr.GetWeederRegistration(namespace + "/" + kubeAPIServer)
+	g.Expect(ok).To(BeFalse())
+	reconcileService(kubeAPIServer)
+	_, ok = weederMgr.GetWeederRegistration(namespace + "/" + kubeAPIServer)
+	g.Expect(ok).To(BeTrue(), "the weeder of a service should be started although its readiness has been checked before")
+}
+
+func TestDependantServiceWeederShouldOnlyBeStartedOnItsOwnReadyTransition(t *testing.T) {
+	const (
+		namespace     = "shoot--project--name"
+		kubeAPIServer = "kube-apiserver"
+	)
+	g := NewWithT(t)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	weederMgr := weeder.NewManager()
+	defer weederMgr.UnregisterAll()
+	// kube-apiserver is still reported ready, although it will fail as its upstream service has failed before.
+	readyServices := sets.New(epName, kubeAPIServer)
+	r := &EndpointSliceReconciler{
+		Client: createReadyServicesClient(t, readyServices),
+		WeederConfig: &wapi.Config{
+			WatchDuration: &metav1.Duration{Duration: time.Minute},
+			ServicesAndDependantSelectors: map[string]wapi.DependantSelectors{
+				epName:        {DependantServices: []string{kubeAPIServer}},
+				kubeAPIServer: {},
+			},
+		},
+		WeederMgr: weederMgr,
+	}
+	apiServer := types.NamespacedName{Namespace: namespace, Name: kubeAPIServer}
+	r.updateServiceReadiness(apiServer, true)
+	reconcileService := func(name string) {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+
+	reconcileService(epName)
+	_, ok := weederMgr.GetWeederRegistration(namespace + "/" + epName)
+	g.Expect(ok).To(BeTrue())
+	_, ok = weederMgr.GetWeederRegistration(namespace + "/" + kubeAPIServer)
+	g.Expect(ok).To(BeFalse(), "the weeder of a dependant service should not be started on a readiness which might be stale")
+	g.Expect(weederMgr.GetDependencyChain(apiServer)).To(Equal([]string{epName, kubeAPIServer}))
+
+	readyServices.Delete(kubeAPIServer)
+	reconcileService(kubeAPIServer)
+	_, ok = weederMgr.GetWeederRegistration(namespace + "/" + kubeAPIServer)
+	g.Expect(ok).To(BeFalse())
+	readyServices.Insert(kubeAPIServer)
+	reconcileService(kubeAPIServer)
+	_, ok = weederMgr.GetWeederRegistration(namespace + "/" + kubeAPIServer)
+	g.Expect(ok).To(BeTrue(), "the weeder of a dependant service should be started once it turns ready after its upstream service has recovered")
+}
+
+// createReadyServicesClient creates a client which lists an EndpointSlice with a ready endpoint for each of the services which
+// are in readyServices at the time of the call.
+func createReadyServicesClient(t *testing.T, readyServices sets.Set[string]) client.Client {
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+		listOpts := &client.ListOptions{}
+		listOpts.ApplyOptions(opts)
+		endpointSlices := list.(*discoveryv1.EndpointSliceList)
+		for service := range readyServices {
+			if listOpts.LabelSelector.Matches(labels.Set{discoveryv1.LabelServiceName: service}) {
+				endpointSlices.Items = append(endpointSlices.Items, createEndpointSlice(service+"-abcde", pointer.Bool(true)))
+			}
+		}
+		return nil
+	}).AnyTimes()
+	return mockClient
+}
+
+func TestMapEndpointSliceToService(t *testing.T) {
+	g := NewWithT(t)
+	endpointSlice := createEndpointSlice("etcd-main-abcde", pointer.Bool(true))
+	endpointSlice.Namespace = "shoot--project--name"
+	g.Expect(mapEndpointSliceToService(context.Background(), &endpointSlice)).To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "shoot--project--name", Name: epName}}))
+
+	delete(endpointSlice.Labels, discoveryv1.LabelServiceName)
+	g.Expect(mapEndpointSliceToService(context.Background(), &endpointSlice)).To(BeEmpty(), "an endpoint slice which does not belong to a service should be ignored")
+}
+
+func TestMatchingEndpointSlices(t *testing.T) {
+	g := NewWithT(t)
+	predicate := MatchingEndpointSlices(sets.New(epName))
+	matchingSlice := createEndpointSlice("etcd-main-abcde", pointer.Bool(true))
+	otherSlice := createEndpointSlice("kube-apiserver-abcde", pointer.Bool(true))
+	otherSlice.Labels[discoveryv1.LabelServiceName] = "kube-apiserver"
+
+	g.Expect(predicate.Create(event.CreateEvent{Object: &matchingSlice})).To(BeTrue())
+	g.Expect(predicate.Update(event.UpdateEvent{ObjectOld: &matchingSlice, ObjectNew: &matchingSlice})).To(BeTrue())
+	g.Expect(predicate.Delete(event.DeleteEvent{Object: &matchingSlice})).To(BeTrue(), "deleting an endpoint slice can change the readiness of its service")
+	g.Expect(predicate.Create(event.CreateEvent{Object: &otherSlice})).To(BeFalse())
+	g.Expect(predicate.Delete(event.DeleteEvent{Object: &otherSlice})).To(BeFalse())
+}
+
+func createEndpointSlice(name string, ready *bool) discoveryv1.EndpointSlice {
+	return discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{discoveryv1.LabelServiceName: epName},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				Addresses:  []string{"10.1.0.52"},
+				Conditions: discoveryv1.EndpointConditions{Ready: ready},
+			},
+		},
+	}
+}
+
+func TestGetNotReadyServices(t *testing.T) {
+	g := NewWithT(t)
+	readyServices := sets.New("etcd-main", "etcd-events")
+	var checkedServices []string
+	isServiceReady := func(_ context.Context, service types.NamespacedName) (bool, error) {
+		checkedServices = append(checkedServices, service.Name)
+		return readyServices.Has(service.Name), nil
+	}
+	service := types.NamespacedName{Namespace: "test", Name: "etcd-main"}
+
+	notReadyServices, err := getNotReadyServices(context.Background(), service, []string{"etcd-main", "etcd-events"}, isServiceReady)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(notReadyServices).To(BeEmpty())
+	g.Expect(checkedServices).To(Equal([]string{"etcd-events"}), "the readiness of the triggering service should not be checked again")
+
+	notReadyServices, err = getNotReadyServices(context.Background(), service, []string{"etcd-main", "etcd-events", "kube-apiserver"}, isServiceReady)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(notReadyServices).To(Equal([]string{"kube-apiserver"}))
+}
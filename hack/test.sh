@@ -17,5 +17,6 @@ export KUBEBUILDER_ATTACH_CONTROL_PLANE_OUTPUT=true
 go test -v ./controllers/cluster
 go test -v ./controllers/endpoint
 go test -v ./internal/...
+go test -v ./webhooks/...
 
 
@@ -2,5 +2,5 @@
 //
 // SPDX-License-Identifier: Apache-2.0
 
-//go:generate mockgen -package scale -destination=mocks.go k8s.io/client-go/scale ScaleInterface
+//go:generate mockgen -package scale -destination=mocks.go k8s.io/client-go/scale ScaleInterface,ScalesGetter
 package scale
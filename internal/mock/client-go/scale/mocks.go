@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: k8s.io/client-go/scale (interfaces: ScaleInterface)
+// Source: k8s.io/client-go/scale (interfaces: ScaleInterface,ScalesGetter)
 
 // Package scale is a generated GoMock package.
 package scale
@@ -13,6 +13,7 @@ import (
 	v10 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	types "k8s.io/apimachinery/pkg/types"
+	scale "k8s.io/client-go/scale"
 )
 
 // MockScaleInterface is a mock of ScaleInterface interface.
@@ -82,3 +83,40 @@ func (mr *MockScaleInterfaceMockRecorder) Update(arg0, arg1, arg2, arg3 interfac
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockScaleInterface)(nil).Update), arg0, arg1, arg2, arg3)
 }
+
+// MockScalesGetter is a mock of ScalesGetter interface.
+type MockScalesGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockScalesGetterMockRecorder
+}
+
+// MockScalesGetterMockRecorder is the mock recorder for MockScalesGetter.
+type MockScalesGetterMockRecorder struct {
+	mock *MockScalesGetter
+}
+
+// NewMockScalesGetter creates a new mock instance.
+func NewMockScalesGetter(ctrl *gomock.Controller) *MockScalesGetter {
+	mock := &MockScalesGetter{ctrl: ctrl}
+	mock.recorder = &MockScalesGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScalesGetter) EXPECT() *MockScalesGetterMockRecorder {
+	return m.recorder
+}
+
+// Scales mocks base method.
+func (m *MockScalesGetter) Scales(arg0 string) scale.ScaleInterface {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Scales", arg0)
+	ret0, _ := ret[0].(scale.ScaleInterface)
+	return ret0
+}
+
+// Scales indicates an expected call of Scales.
+func (mr *MockScalesGetterMockRecorder) Scales(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scales", reflect.TypeOf((*MockScalesGetter)(nil).Scales), arg0)
+}
@@ -34,30 +34,59 @@ func (m *MockScaler) EXPECT() *MockScalerMockRecorder {
 	return m.recorder
 }
 
+// IsScaledDown mocks base method.
+func (m *MockScaler) IsScaledDown(arg0 context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsScaledDown", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsScaledDown indicates an expected call of IsScaledDown.
+func (mr *MockScalerMockRecorder) IsScaledDown(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsScaledDown", reflect.TypeOf((*MockScaler)(nil).IsScaledDown), arg0)
+}
+
+// RecordScaleUpGate mocks base method.
+func (m *MockScaler) RecordScaleUpGate(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordScaleUpGate", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordScaleUpGate indicates an expected call of RecordScaleUpGate.
+func (mr *MockScalerMockRecorder) RecordScaleUpGate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordScaleUpGate", reflect.TypeOf((*MockScaler)(nil).RecordScaleUpGate), arg0, arg1)
+}
+
 // ScaleDown mocks base method.
-func (m *MockScaler) ScaleDown(arg0 context.Context) error {
+func (m *MockScaler) ScaleDown(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ScaleDown", arg0)
+	ret := m.ctrl.Call(m, "ScaleDown", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ScaleDown indicates an expected call of ScaleDown.
-func (mr *MockScalerMockRecorder) ScaleDown(arg0 interface{}) *gomock.Call {
+func (mr *MockScalerMockRecorder) ScaleDown(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScaleDown", reflect.TypeOf((*MockScaler)(nil).ScaleDown), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScaleDown", reflect.TypeOf((*MockScaler)(nil).ScaleDown), arg0, arg1)
 }
 
 // ScaleUp mocks base method.
-func (m *MockScaler) ScaleUp(arg0 context.Context) error {
+func (m *MockScaler) ScaleUp(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ScaleUp", arg0)
+	ret := m.ctrl.Call(m, "ScaleUp", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ScaleUp indicates an expected call of ScaleUp.
-func (mr *MockScalerMockRecorder) ScaleUp(arg0 interface{}) *gomock.Call {
+func (mr *MockScalerMockRecorder) ScaleUp(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScaleUp", reflect.TypeOf((*MockScaler)(nil).ScaleUp), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScaleUp", reflect.TypeOf((*MockScaler)(nil).ScaleUp), arg0, arg1)
 }
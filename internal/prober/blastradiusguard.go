@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package prober
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ScaleDownGuard decides if a prober is allowed to scale down its dependent resources. It is shared by all probers.
+type ScaleDownGuard interface {
+	// AdmitScaleDown records that the prober for the given namespace wants to scale down. It returns false if the scale-down has to be held back.
+	AdmitScaleDown(namespace string) bool
+	// Release records that the prober for the given namespace no longer wants to scale down, e.g. because its lease probe has succeeded.
+	Release(namespace string)
+}
+
+// blastRadiusGuard holds back scale-downs across all probers once more probers than allowed want to scale down within a window.
+// Probers which have been admitted to scale down before the guard has tripped stay admitted until they are released, so that
+// their scale-down can be re-attempted and is not interrupted.
+type blastRadiusGuard struct {
+	sync.Mutex
+	config *papi.BlastRadiusGuard
+	// probers are the namespaces of all registered probers.
+	probers sets.Set[string]
+	// scaleDownRequests holds for each prober which wants to scale down, the time at which it has last asked to scale down.
+	scaleDownRequests map[string]time.Time
+	// admitted are the namespaces of the probers which have been allowed to scale down.
+	admitted sets.Set[string]
+	tripped  bool
+	l        logr.Logger
+}
+
+func newBlastRadiusGuard(config *papi.BlastRadiusGuard, logger logr.Logger) *blastRadiusGuard {
+	return &blastRadiusGuard{
+		config:            config,
+		probers:           sets.New[string](),
+		scaleDownRequests: make(map[string]time.Time),
+		admitted:          sets.New[string](),
+		l:                 logger,
+	}
+}
+
+func (g *blastRadiusGuard) AdmitScaleDown(namespace string) bool {
+	return g.admitScaleDown(namespace, time.Now())
+}
+
+func (g *blastRadiusGuard) Release(namespace string) {
+	g.release(namespace, time.Now())
+}
+
+// register records that a prober has been registered for the given namespace.
+func (g *blastRadiusGuard) register(namespace string) {
+	g.Lock()
+	defer g.Unlock()
+	g.probers.Insert(namespace)
+}
+
+// unregister forgets the prober for the given namespace.
+func (g *blastRadiusGuard) unregister(namespace string) {
+	g.Lock()
+	defer g.Unlock()
+	g.probers.Delete(namespace)
+	g.forget(namespace)
+	g.evaluate(time.Now())
+}
+
+func (g *blastRadiusGuard) admitScaleDown(namespace string, now time.Time) bool {
+	g.Lock()
+	defer g.Unlock()
+	g.scaleDownRequests[namespace] = now
+	g.evaluate(now)
+	if g.admitted.Has(namespace) {
+		return true
+	}
+	if g.tripped {
+		return false
+	}
+	g.admitted.Insert(namespace)
+	return true
+}
+
+func (g *blastRadiusGuard) release(namespace string, now time.Time) {
+	g.Lock()
+	defer g.Unlock()
+	if _, ok := g.scaleDownRequests[namespace]; !ok && !g.admitted.Has(namespace) {
+		return
+	}
+	g.forget(namespace)
+	g.evaluate(now)
+}
+
+func (g *blastRadiusGuard) forget(namespace string) {
+	delete(g.scaleDownRequests, namespace)
+	g.admitted.Delete(namespace)
+}
+
+// evaluate drops the scale-down requests which are older than the window and trips or resets the guard depending on the
+// number of probers which want to scale down. It has to be called with the lock held.
+func (g *blastRadiusGuard) evaluate(now time.Time) {
+	for namespace, requestedAt := range g.scaleDownRequests {
+		if now.Sub(requestedAt) > g.config.Window.Duration {
+			delete(g.scaleDownRequests, namespace)
+		}
+	}
+	requestCount, limit := len(g.scaleDownRequests), g.limit()
+	switch {
+	case requestCount > limit && !g.tripped:
+		g.tripped = true
+		blastRadiusGuardTripped.Set(1)
+		blastRadiusGuardTripsTotal.Inc()
+		g.l.Error(nil, "Too many probers want to scale down, this is probably caused by a seed-side fault. Further scale downs are held back",
+			"scaleDownRequests", requestCount, "limit", limit, "probers", g.probers.Len(), "window", g.config.Window.Duration)
+	case requestCount <= limit && g.tripped:
+		g.tripped = false
+		blastRadiusGuardTripped.Set(0)
+		g.l.Info("Number of probers which want to scale down is within the limit again, scale downs are no longer held back",
+			"scaleDownRequests", requestCount, "limit", limit, "probers", g.probers.Len())
+	}
+	heldScaleDowns.Set(float64(g.countHeld()))
+}
+
+// limit returns the maximum number of probers which are allowed to scale down within the window. MaxScaleDownFraction is
+// rounded up, so that at least one prober is allowed to scale down.
+func (g *blastRadiusGuard) limit() int {
+	limit := math.MaxInt
+	if g.config.MaxScaleDowns != nil {
+		limit = *g.config.MaxScaleDowns
+	}
+	if g.config.MaxScaleDownFraction != nil {
+		fractionLimit := int(math.Ceil(*g.config.MaxScaleDownFraction * float64(g.probers.Len())))
+		limit = min(limit, max(fractionLimit, 1))
+	}
+	return limit
+}
+
+// countHeld returns the number of probers which want to scale down but have not been admitted.
+func (g *blastRadiusGuard) countHeld() int {
+	if !g.tripped {
+		return 0
+	}
+	held := 0
+	for namespace := range g.scaleDownRequests {
+		if !g.admitted.Has(namespace) {
+			held++
+		}
+	}
+	return held
+}
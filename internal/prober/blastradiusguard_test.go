@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package prober
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestBlastRadiusGuardShouldHoldScaleDownsAboveMaxScaleDowns(t *testing.T) {
+	g := NewWithT(t)
+	guard := createBlastRadiusGuard(pointer.Int(2), nil, 10)
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	g.Expect(guard.admitScaleDown("shoot-0", now)).To(BeTrue())
+	g.Expect(guard.admitScaleDown("shoot-1", now)).To(BeTrue())
+	g.Expect(guard.admitScaleDown("shoot-2", now)).To(BeFalse(), "a scale down above maxScaleDowns should be held back")
+	g.Expect(guard.tripped).To(BeTrue())
+	g.Expect(gaugeValue(blastRadiusGuardTripped)).To(Equal(1.0))
+	g.Expect(gaugeValue(heldScaleDowns)).To(Equal(1.0))
+	g.Expect(guard.admitScaleDown("shoot-0", now.Add(10*time.Second))).To(BeTrue(), "an admitted prober should stay admitted while the guard is tripped")
+
+	guard.release("shoot-1", now.Add(20*time.Second))
+	g.Expect(guard.tripped).To(BeFalse(), "the guard should be reset once the scale down requests are within the limit")
+	g.Expect(gaugeValue(blastRadiusGuardTripped)).To(Equal(0.0))
+	g.Expect(gaugeValue(heldScaleDowns)).To(Equal(0.0))
+	g.Expect(guard.admitScaleDown("shoot-2", now.Add(30*time.Second))).To(BeTrue())
+}
+
+func TestBlastRadiusGuardShouldHoldScaleDownsAboveMaxScaleDownFraction(t *testing.T) {
+	g := NewWithT(t)
+	guard := createBlastRadiusGuard(nil, pointer.Float64(0.25), 10)
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// 0.25 of 10 probers is rounded up to 3
+	for i := 0; i < 3; i++ {
+		g.Expect(guard.admitScaleDown(fmt.Sprintf("shoot-%d", i), now)).To(BeTrue())
+	}
+	g.Expect(guard.admitScaleDown("shoot-3", now)).To(BeFalse())
+	g.Expect(guard.admitScaleDown("shoot-4", now)).To(BeFalse())
+	g.Expect(guard.countHeld()).To(Equal(2))
+}
+
+func TestBlastRadiusGuardShouldOnlyCountScaleDownRequestsWithinWindow(t *testing.T) {
+	g := NewWithT(t)
+	guard := createBlastRadiusGuard(pointer.Int(1), nil, 10)
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	g.Expect(guard.admitScaleDown("shoot-0", now)).To(BeTrue())
+	g.Expect(guard.admitScaleDown("shoot-1", now.Add(time.Minute))).To(BeFalse())
+	// shoot-0 has not asked to scale down again within the window
+	g.Expect(guard.admitScaleDown("shoot-1", now.Add(6*time.Minute))).To(BeTrue())
+	g.Expect(guard.tripped).To(BeFalse())
+}
+
+func TestBlastRadiusGuardShouldForgetUnregisteredProbers(t *testing.T) {
+	g := NewWithT(t)
+	guard := createBlastRadiusGuard(pointer.Int(1), nil, 3)
+	now := time.Now()
+
+	g.Expect(guard.admitScaleDown("shoot-0", now)).To(BeTrue())
+	g.Expect(guard.admitScaleDown("shoot-1", now)).To(BeFalse())
+	guard.unregister("shoot-0")
+	g.Expect(guard.tripped).To(BeFalse())
+	g.Expect(guard.probers.Has("shoot-0")).To(BeFalse())
+	g.Expect(guard.admitScaleDown("shoot-1", now)).To(BeTrue())
+}
+
+func createBlastRadiusGuard(maxScaleDowns *int, maxScaleDownFraction *float64, proberCount int) *blastRadiusGuard {
+	guard := newBlastRadiusGuard(&papi.BlastRadiusGuard{
+		Window:               &metav1.Duration{Duration: 5 * time.Minute},
+		MaxScaleDowns:        maxScaleDowns,
+		MaxScaleDownFraction: maxScaleDownFraction,
+	}, logr.Discard())
+	for i := 0; i < proberCount; i++ {
+		guard.register(fmt.Sprintf("shoot-%d", i))
+	}
+	return guard
+}
+
+func gaugeValue(gauge interface{ Write(*dto.Metric) error }) float64 {
+	m := &dto.Metric{}
+	if err := gauge.Write(m); err != nil {
+		return -1
+	}
+	return m.GetGauge().GetValue()
+}
+
+func counterValue(counter interface{ Write(*dto.Metric) error }) float64 {
+	m := &dto.Metric{}
+	if err := counter.Write(m); err != nil {
+		return -1
+	}
+	return m.GetCounter().GetValue()
+}
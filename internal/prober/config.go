@@ -5,12 +5,19 @@
 package prober
 
 import (
+	"fmt"
+	"net/url"
 	"time"
 
 	papi "github.com/gardener/dependency-watchdog/api/prober"
 	"github.com/gardener/dependency-watchdog/internal/util"
+	multierr "github.com/hashicorp/go-multierror"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/scale"
 )
 
 const (
@@ -36,6 +43,18 @@ const (
 	// See https://kubernetes.io/docs/reference/command-line-tools-reference/kube-controller-manager/#:~:text=%2D%2Dnode%2Dmonitor%2Dgrace%2Dperiod%20duration
 	// Note: Make sure to keep this value in sync with default value of nodeMonitorGracePeriod in KCM.
 	DefaultKCMNodeMonitorGraceDuration = 40 * time.Second
+	// DefaultReadyNodeFraction is the default minimum fraction of shoot nodes which need to be ready for the node readiness gate to pass.
+	DefaultReadyNodeFraction = 0.8
+	// DefaultNodeReadinessSettlePeriod is the default duration for which the ready node fraction needs to be met for the node readiness gate to pass.
+	DefaultNodeReadinessSettlePeriod = 1 * time.Minute
+	// DefaultScaleHookTimeout is the default timeout for a single call to a scale hook.
+	DefaultScaleHookTimeout = 5 * time.Second
+	// DefaultBlastRadiusGuardWindow is the default duration within which probers which want to scale down are counted by the blast radius guard.
+	DefaultBlastRadiusGuardWindow = 5 * time.Minute
+	// sampleShootNamespace is used to validate namespace templates of dependent resources.
+	sampleShootNamespace = "shoot--project--name"
+	// otherSampleShootNamespace is used to check that namespace templates of dependent resources depend on the shoot namespace.
+	otherSampleShootNamespace = "shoot--project--other"
 )
 
 // LoadConfig reads the prober configuration from a file, unmarshalls it, fills in the default values and
@@ -62,16 +81,133 @@ func validate(c *papi.Config, scheme *runtime.Scheme) error {
 	}
 	v.MustNotBeEmpty("ScaleResourceInfos", c.DependentResourceInfos)
 	for _, resInfo := range c.DependentResourceInfos {
-		v.ResourceRefMustBeValid(resInfo.Ref, scheme)
+		validateResourceIdentifier(v, resInfo, scheme)
+		validateNamespace(v, resInfo)
 		v.MustNotBeNil("scaleUp", resInfo.ScaleUpInfo)
 		v.MustNotBeNil("scaleDown", resInfo.ScaleDownInfo)
 	}
+	validateNodeReadinessGate(v, c.NodeReadinessGate)
+	validateScaleHooks(v, c.ScaleHooks)
+	if c.MaxScaledDownDuration != nil {
+		v.MustNotBeZeroDuration("maxScaledDownDuration", *c.MaxScaledDownDuration)
+	}
+	validateBlastRadiusGuard(v, c.BlastRadiusGuard)
 	if v.Error != nil {
 		return v.Error
 	}
 	return nil
 }
 
+// ValidateScaleSubresources validates that every dependent resource kind in the configuration is served by the API server and
+// exposes a scale subresource. Unlike the validations done by LoadConfig it requires access to the API server, therefore it is
+// done separately once the prober has been started. This allows to detect misconfigured dependent resources upfront instead of
+// only when they have to be scaled.
+func ValidateScaleSubresources(config *papi.Config, mapper meta.RESTMapper, resolver scale.ScaleKindResolver) error {
+	v := new(util.Validator)
+	for _, resInfo := range config.DependentResourceInfos {
+		resourceRef := resInfo.Ref
+		if resourceRef == nil {
+			resourceRef = &autoscalingv1.CrossVersionObjectReference{Kind: resInfo.Selector.Kind, APIVersion: resInfo.Selector.APIVersion}
+		}
+		v.ScaleSubresourceMustBeSupported(resourceRef, mapper, resolver)
+	}
+	return v.Error
+}
+
+// validateNodeReadinessGate validates that the ready node fraction of the node readiness gate is within (0, 1] and that its
+// settle period is not negative.
+func validateNodeReadinessGate(v *util.Validator, gate *papi.NodeReadinessGate) {
+	if gate == nil {
+		return
+	}
+	if *gate.ReadyNodeFraction <= 0 || *gate.ReadyNodeFraction > 1 {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("nodeReadinessGate.readyNodeFraction must be greater than 0 and at most 1, found %v", *gate.ReadyNodeFraction))
+	}
+	if gate.SettlePeriod.Duration < 0 {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("nodeReadinessGate.settlePeriod must not be negative, found %s", gate.SettlePeriod.Duration))
+	}
+}
+
+// validateBlastRadiusGuard validates that the blast radius guard has a non-zero window and at least one limit, and that
+// the configured limits allow at least one prober to scale down.
+func validateBlastRadiusGuard(v *util.Validator, guard *papi.BlastRadiusGuard) {
+	if guard == nil {
+		return
+	}
+	v.MustNotBeZeroDuration("blastRadiusGuard.window", *guard.Window)
+	if guard.MaxScaleDowns == nil && guard.MaxScaleDownFraction == nil {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("at least one of blastRadiusGuard.maxScaleDowns and blastRadiusGuard.maxScaleDownFraction must be set"))
+	}
+	if guard.MaxScaleDowns != nil && *guard.MaxScaleDowns < 1 {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("blastRadiusGuard.maxScaleDowns must be at least 1, found %d", *guard.MaxScaleDowns))
+	}
+	if guard.MaxScaleDownFraction != nil && (*guard.MaxScaleDownFraction <= 0 || *guard.MaxScaleDownFraction > 1) {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("blastRadiusGuard.maxScaleDownFraction must be greater than 0 and at most 1, found %v", *guard.MaxScaleDownFraction))
+	}
+}
+
+// validateScaleHooks validates that each scale hook has a unique name, an absolute HTTP(S) URL and a known failure policy.
+func validateScaleHooks(v *util.Validator, hooks []papi.ScaleHook) {
+	names := sets.New[string]()
+	for _, hook := range hooks {
+		if v.MustNotBeEmpty("scaleHooks.name", hook.Name) {
+			if names.Has(hook.Name) {
+				v.Error = multierr.Append(v.Error, fmt.Errorf("scaleHooks.name %s is not unique", hook.Name))
+			}
+			names.Insert(hook.Name)
+		}
+		if hookURL, err := url.Parse(hook.URL); err != nil || (hookURL.Scheme != "http" && hookURL.Scheme != "https") || hookURL.Host == "" {
+			v.Error = multierr.Append(v.Error, fmt.Errorf("scaleHooks.url %q of scale hook %s must be an absolute http or https URL", hook.URL, hook.Name))
+		}
+		if *hook.FailurePolicy != papi.ScaleHookFailurePolicyIgnore && *hook.FailurePolicy != papi.ScaleHookFailurePolicyBlock {
+			v.Error = multierr.Append(v.Error, fmt.Errorf("scaleHooks.failurePolicy %s of scale hook %s must be one of %s, %s", *hook.FailurePolicy, hook.Name, papi.ScaleHookFailurePolicyIgnore, papi.ScaleHookFailurePolicyBlock))
+		}
+		v.MustNotBeZeroDuration("scaleHooks.timeout", *hook.Timeout)
+	}
+}
+
+// validateResourceIdentifier validates that a dependent resource is identified either via a Ref or via a Selector but not both.
+func validateResourceIdentifier(v *util.Validator, resInfo papi.DependentResourceInfo, scheme *runtime.Scheme) {
+	if (resInfo.Ref == nil) == (resInfo.Selector == nil) {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("exactly one of ref or selector must be set for a dependent resource"))
+		return
+	}
+	if resInfo.Ref != nil {
+		v.ResourceRefMustBeValid(resInfo.Ref, scheme)
+		return
+	}
+	v.ResourceRefMustBeValid(&autoscalingv1.CrossVersionObjectReference{Kind: resInfo.Selector.Kind, APIVersion: resInfo.Selector.APIVersion}, scheme)
+	if v.MustNotBeNil("selector.labelSelector", resInfo.Selector.LabelSelector) {
+		if _, err := metav1.LabelSelectorAsSelector(resInfo.Selector.LabelSelector); err != nil {
+			v.Error = multierr.Append(v.Error, err)
+		}
+	}
+}
+
+// validateNamespace validates that the namespace of a dependent resource can be rendered into a valid namespace name.
+// As the shoot namespace is only known when a probe is created, a sample shoot namespace is used for rendering.
+// A namespace which is rendered identically for different shoot namespaces is rejected, see newSharedNamespaceError. The Manager rejects
+// a prober whose namespace template renders the same namespace as the one of another shoot, which cannot be detected with sample namespaces.
+func validateNamespace(v *util.Validator, resInfo papi.DependentResourceInfo) {
+	namespace, err := util.RenderNamespace(resInfo.Namespace, sampleShootNamespace)
+	if err != nil {
+		v.Error = multierr.Append(v.Error, err)
+		return
+	}
+	if otherNamespace, err := util.RenderNamespace(resInfo.Namespace, otherSampleShootNamespace); err == nil && otherNamespace == namespace {
+		v.Error = multierr.Append(v.Error, newSharedNamespaceError(resInfo.Namespace))
+	}
+}
+
+// newSharedNamespaceError returns the error for a namespace of dependent resources which would be shared by the probers of several shoots.
+// Shared namespaces are not supported: the names and selectors of dependent resources are not rendered per shoot, so the probers would
+// scale the same resources and overwrite each other's replicas and annotations.
+func newSharedNamespaceError(namespace string) error {
+	return fmt.Errorf("namespace %q of dependent resources is shared by the probers of several shoots, which is not supported as the names and selectors "+
+		"of dependent resources are the same for all shoots and the probers would scale the same resources: use a namespace template which renders "+
+		"a namespace per shoot, e.g. \"{{ .ShootNamespace }}-extension\"", namespace)
+}
+
 func fillDefaultValues(c *papi.Config) {
 	c.ProbeInterval = util.GetValOrDefault(c.ProbeInterval, metav1.Duration{Duration: DefaultProbeInterval})
 	c.InitialDelay = util.GetValOrDefault(c.InitialDelay, metav1.Duration{Duration: DefaultProbeInitialDelay})
@@ -80,6 +216,25 @@ func fillDefaultValues(c *papi.Config) {
 	c.NodeLeaseFailureFraction = util.GetValOrDefault(c.NodeLeaseFailureFraction, DefaultNodeLeaseFailureFraction)
 	c.KCMNodeMonitorGraceDuration = util.GetValOrDefault(c.KCMNodeMonitorGraceDuration, metav1.Duration{Duration: DefaultKCMNodeMonitorGraceDuration})
 	fillDefaultValuesForResourceInfos(c.DependentResourceInfos)
+	fillDefaultValuesForNodeReadinessGate(c.NodeReadinessGate)
+	fillDefaultValuesForScaleHooks(c.ScaleHooks)
+	if c.BlastRadiusGuard != nil {
+		c.BlastRadiusGuard.Window = util.GetValOrDefault(c.BlastRadiusGuard.Window, metav1.Duration{Duration: DefaultBlastRadiusGuardWindow})
+	}
+}
+
+func fillDefaultValuesForScaleHooks(hooks []papi.ScaleHook) {
+	for i := range hooks {
+		hooks[i].Timeout = util.GetValOrDefault(hooks[i].Timeout, metav1.Duration{Duration: DefaultScaleHookTimeout})
+		hooks[i].FailurePolicy = util.GetValOrDefault(hooks[i].FailurePolicy, papi.ScaleHookFailurePolicyIgnore)
+	}
+}
+
+func fillDefaultValuesForNodeReadinessGate(gate *papi.NodeReadinessGate) {
+	if gate != nil {
+		gate.ReadyNodeFraction = util.GetValOrDefault(gate.ReadyNodeFraction, DefaultReadyNodeFraction)
+		gate.SettlePeriod = util.GetValOrDefault(gate.SettlePeriod, metav1.Duration{Duration: DefaultNodeReadinessSettlePeriod})
+	}
 }
 
 func fillDefaultValuesForResourceInfos(resourceInfos []papi.DependentResourceInfo) {
@@ -10,8 +10,11 @@ import (
 	"fmt"
 	"path/filepath"
 	"testing"
+	"time"
 
+	papi "github.com/gardener/dependency-watchdog/api/prober"
 	testutil "github.com/gardener/dependency-watchdog/internal/test"
+	"github.com/gardener/dependency-watchdog/internal/util"
 	multierr "github.com/hashicorp/go-multierror"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
@@ -33,6 +36,12 @@ func TestProberConfigSuite(t *testing.T) {
 		{"config file not found", testConfigFileNotFound},
 		{"invalid configuration yaml", testErrorInUnMarshallingYaml},
 		{"valid configuration yaml", testValidConfigShouldPassAllValidations},
+		{"valid configuration yaml with resource selector", testValidConfigWithResourceSelectorShouldPassAllValidations},
+		{"valid configuration yaml with resource namespaces", testValidConfigWithResourceNamespacesShouldPassAllValidations},
+		{"shared resource namespace should be rejected", testSharedResourceNamespaceShouldBeRejected},
+		{"valid configuration yaml with node readiness gate", testValidConfigWithNodeReadinessGateShouldPassAllValidations},
+		{"valid configuration yaml with scale hooks", testValidConfigWithScaleHooksShouldPassAllValidations},
+		{"valid configuration yaml with blast radius guard", testValidConfigWithBlastRadiusGuardShouldPassAllValidations},
 	}
 
 	scheme := runtime.NewScheme()
@@ -77,6 +86,11 @@ func testMissingConfigValuesShouldReturnErrorAndNilConfig(t *testing.T, s *runti
 	}{
 		{"config_missing_mandatory_values.yaml", 5},
 		{"config_missing_dependent_resource_infos.yaml", 2},
+		{"config_invalid_resource_identifiers.yaml", 3},
+		{"config_invalid_resource_namespaces.yaml", 4},
+		{"config_invalid_node_readiness_gate.yaml", 2},
+		{"config_invalid_scale_hooks.yaml", 5},
+		{"config_invalid_blast_radius_guard.yaml", 3},
 	}
 
 	for _, entry := range table {
@@ -128,3 +142,102 @@ func testValidConfigShouldPassAllValidations(t *testing.T, s *runtime.Scheme) {
 
 	t.Log("Valid config is loaded correctly")
 }
+
+func testValidConfigWithResourceSelectorShouldPassAllValidations(t *testing.T, s *runtime.Scheme) {
+	g := NewWithT(t)
+	testutil.ValidateIfFileExists(testdataPath, t)
+
+	configPath := filepath.Join(testdataPath, "valid_config_with_resource_selector.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath, s)
+	g.Expect(err).ToNot(HaveOccurred(), "LoadConfig should not give error for a valid config")
+	g.Expect(config).ToNot(BeNil(), "LoadConfig should got nil config for a valid file")
+	g.Expect(config.DependentResourceInfos).To(HaveLen(2), "LoadConfig did not load all the dependent resources")
+	selector := config.DependentResourceInfos[1].Selector
+	g.Expect(selector).ToNot(BeNil())
+	g.Expect(selector.LabelSelector.MatchLabels).To(HaveKeyWithValue("dependency-watchdog.gardener.cloud/scale-on-connectivity-loss", "true"))
+	g.Expect(config.DependentResourceInfos[1].ScaleDownInfo.Timeout.Duration).To(Equal(DefaultScaleUpdateTimeout), "LoadConfig should set default values for resource selectors")
+
+	t.Log("Valid config with resource selector is loaded correctly")
+}
+
+func testValidConfigWithResourceNamespacesShouldPassAllValidations(t *testing.T, s *runtime.Scheme) {
+	g := NewWithT(t)
+	testutil.ValidateIfFileExists(testdataPath, t)
+
+	configPath := filepath.Join(testdataPath, "valid_config_with_resource_namespaces.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath, s)
+	g.Expect(err).ToNot(HaveOccurred(), "LoadConfig should not give error for a valid config")
+	g.Expect(config).ToNot(BeNil(), "LoadConfig should got nil config for a valid file")
+	g.Expect(config.DependentResourceInfos).To(HaveLen(3), "LoadConfig did not load all the dependent resources")
+	g.Expect(config.DependentResourceInfos[0].Namespace).To(BeEmpty())
+	g.Expect(config.DependentResourceInfos[1].Namespace).To(Equal("{{ .ShootNamespace }}-extension"))
+	g.Expect(config.DependentResourceInfos[2].Namespace).To(Equal("{{ .ShootNamespace }}-shared"))
+
+	t.Log("Valid config with resource namespaces is loaded correctly")
+}
+
+func testSharedResourceNamespaceShouldBeRejected(t *testing.T, _ *runtime.Scheme) {
+	g := NewWithT(t)
+	for _, namespace := range []string{"shared-components", "{{ slice .ShootNamespace 0 7 }}-shared"} {
+		v := &util.Validator{}
+		validateNamespace(v, papi.DependentResourceInfo{Namespace: namespace})
+		g.Expect(v.Error).To(HaveOccurred(), "namespace %q is shared by all shoots and should be rejected", namespace)
+		g.Expect(v.Error.Error()).To(ContainSubstring("which is not supported"), "the error should explain why shared namespaces are rejected")
+	}
+	v := &util.Validator{}
+	validateNamespace(v, papi.DependentResourceInfo{Namespace: "{{ .ShootNamespace }}-extension"})
+	g.Expect(v.Error).ToNot(HaveOccurred())
+}
+
+func testValidConfigWithNodeReadinessGateShouldPassAllValidations(t *testing.T, s *runtime.Scheme) {
+	g := NewWithT(t)
+	testutil.ValidateIfFileExists(testdataPath, t)
+
+	configPath := filepath.Join(testdataPath, "valid_config_with_node_readiness_gate.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath, s)
+	g.Expect(err).ToNot(HaveOccurred(), "LoadConfig should not give error for a valid config")
+	g.Expect(config).ToNot(BeNil(), "LoadConfig should got nil config for a valid file")
+	g.Expect(config.NodeReadinessGate).ToNot(BeNil())
+	g.Expect(*config.NodeReadinessGate.ReadyNodeFraction).To(Equal(0.9))
+	g.Expect(config.NodeReadinessGate.SettlePeriod.Duration).To(Equal(DefaultNodeReadinessSettlePeriod), "LoadConfig should set the default settle period")
+
+	t.Log("Valid config with node readiness gate is loaded correctly")
+}
+
+func testValidConfigWithScaleHooksShouldPassAllValidations(t *testing.T, s *runtime.Scheme) {
+	g := NewWithT(t)
+	testutil.ValidateIfFileExists(testdataPath, t)
+
+	configPath := filepath.Join(testdataPath, "valid_config_with_scale_hooks.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath, s)
+	g.Expect(err).ToNot(HaveOccurred(), "LoadConfig should not give error for a valid config")
+	g.Expect(config).ToNot(BeNil(), "LoadConfig should got nil config for a valid file")
+	g.Expect(config.ScaleHooks).To(HaveLen(2))
+	g.Expect(config.ScaleHooks[0].Timeout.Duration).To(Equal(DefaultScaleHookTimeout), "LoadConfig should set the default timeout")
+	g.Expect(*config.ScaleHooks[0].FailurePolicy).To(Equal(papi.ScaleHookFailurePolicyIgnore), "LoadConfig should set the default failure policy")
+	g.Expect(config.ScaleHooks[1].Timeout.Duration).To(Equal(2 * time.Second))
+	g.Expect(*config.ScaleHooks[1].FailurePolicy).To(Equal(papi.ScaleHookFailurePolicyBlock))
+
+	t.Log("Valid config with scale hooks is loaded correctly")
+}
+
+func testValidConfigWithBlastRadiusGuardShouldPassAllValidations(t *testing.T, s *runtime.Scheme) {
+	g := NewWithT(t)
+	testutil.ValidateIfFileExists(testdataPath, t)
+
+	configPath := filepath.Join(testdataPath, "valid_config_with_blast_radius_guard.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath, s)
+	g.Expect(err).ToNot(HaveOccurred(), "LoadConfig should not give error for a valid config")
+	g.Expect(config).ToNot(BeNil(), "LoadConfig should got nil config for a valid file")
+	g.Expect(config.BlastRadiusGuard).ToNot(BeNil())
+	g.Expect(config.BlastRadiusGuard.Window.Duration).To(Equal(DefaultBlastRadiusGuardWindow), "LoadConfig should set the default window")
+	g.Expect(*config.BlastRadiusGuard.MaxScaleDowns).To(Equal(10))
+	g.Expect(*config.BlastRadiusGuard.MaxScaleDownFraction).To(Equal(0.3))
+
+	t.Log("Valid config with blast radius guard is loaded correctly")
+}
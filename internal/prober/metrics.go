@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package prober
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "dwd"
+	metricsSubsystem = "prober"
+)
+
+var (
+	blastRadiusGuardTripped = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "blast_radius_guard_tripped",
+		Help:      "Is 1 while the blast radius guard holds back scale-downs as too many probers of the seed want to scale down, which indicates a probable seed-side fault, else 0.",
+	})
+	blastRadiusGuardTripsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "blast_radius_guard_trips_total",
+		Help:      "Number of times the blast radius guard has started to hold back scale-downs.",
+	})
+	heldScaleDowns = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "held_scale_downs",
+		Help:      "Number of probers which want to scale down but are currently held back by the blast radius guard.",
+	})
+	scaleDownOverridesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "scale_down_overrides_total",
+		Help:      "Number of times the dependent resources of a shoot have been restored as they have been kept scaled down for longer than the maximum scaled down duration.",
+	})
+	scheduledProbes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "scheduled_probes",
+		Help:      "Number of probes which are scheduled by the probe scheduler and are not yet due or wait for a free worker.",
+	})
+	probeSchedulingLagSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "probe_scheduling_lag_seconds",
+		Help:      "Duration between the time at which a probe is due and the time at which a worker of the probe scheduler starts it.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30},
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(blastRadiusGuardTripped, blastRadiusGuardTripsTotal, heldScaleDowns, scaleDownOverridesTotal, scheduledProbes, probeSchedulingLagSeconds)
+}
@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package prober
+
+import (
+	"fmt"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// nodeReadinessGate holds back a scale-up until a configured fraction of the shoot nodes has been ready continuously for a configured settle period.
+type nodeReadinessGate struct {
+	config *papi.NodeReadinessGate
+	// readySince is the time since which the ready node fraction has been met. It is nil if the ready node fraction is currently not met.
+	readySince *time.Time
+	// readyNodeCount and nodeCount are the number of ready nodes and the total number of nodes observed last.
+	readyNodeCount int
+	nodeCount      int
+}
+
+func newNodeReadinessGate(config *papi.NodeReadinessGate) *nodeReadinessGate {
+	return &nodeReadinessGate{config: config}
+}
+
+// observe records the readiness of the shoot nodes at the given time.
+func (g *nodeReadinessGate) observe(nodes []corev1.Node, now time.Time) {
+	g.nodeCount = len(nodes)
+	g.readyNodeCount = 0
+	for _, node := range nodes {
+		if isNodeReady(node) {
+			g.readyNodeCount++
+		}
+	}
+	if !g.isReadyNodeFractionMet() {
+		g.readySince = nil
+		return
+	}
+	if g.readySince == nil {
+		g.readySince = &now
+	}
+}
+
+// reset forgets all observations, so that the settle period starts anew once the ready node fraction is met again.
+func (g *nodeReadinessGate) reset() {
+	g.readySince = nil
+	g.readyNodeCount = 0
+	g.nodeCount = 0
+}
+
+// isOpen checks if the ready node fraction has been met for at least the settle period at the given time.
+func (g *nodeReadinessGate) isOpen(now time.Time) bool {
+	return g.readySince != nil && !now.Before(g.readySince.Add(g.config.SettlePeriod.Duration))
+}
+
+// describe returns a description of why the gate is closed. The description only changes when the state of the gate
+// changes, therefore it is suitable to be recorded on the dependent resources.
+func (g *nodeReadinessGate) describe() string {
+	if g.readySince == nil {
+		return fmt.Sprintf("waiting for at least %.2f of the shoot nodes to be ready", *g.config.ReadyNodeFraction)
+	}
+	return fmt.Sprintf("waiting for the shoot nodes to remain ready until %s", g.readySince.Add(g.config.SettlePeriod.Duration).UTC().Format(time.RFC3339))
+}
+
+func (g *nodeReadinessGate) isReadyNodeFractionMet() bool {
+	if g.nodeCount == 0 {
+		return false
+	}
+	return float64(g.readyNodeCount)/float64(g.nodeCount) >= *g.config.ReadyNodeFraction
+}
+
+func isNodeReady(node corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
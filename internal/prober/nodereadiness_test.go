@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package prober
+
+import (
+	"testing"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestNodeReadinessGateShouldOpenAfterSettlePeriod(t *testing.T) {
+	g := NewWithT(t)
+	gate := newNodeReadinessGate(createNodeReadinessGateConfig(0.75, time.Minute))
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	gate.observe(createNodesWithReadiness(corev1.ConditionTrue, corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionUnknown), now)
+	g.Expect(gate.isOpen(now)).To(BeFalse())
+	g.Expect(gate.describe()).To(Equal("waiting for at least 0.75 of the shoot nodes to be ready"))
+
+	readyNodes := createNodesWithReadiness(corev1.ConditionTrue, corev1.ConditionTrue, corev1.ConditionTrue, corev1.ConditionFalse)
+	gate.observe(readyNodes, now.Add(10*time.Second))
+	g.Expect(gate.isOpen(now.Add(10 * time.Second))).To(BeFalse())
+	g.Expect(gate.describe()).To(Equal("waiting for the shoot nodes to remain ready until 2024-01-01T10:01:10Z"))
+
+	// the settle period should not be restarted as long as the ready node fraction is met
+	gate.observe(readyNodes, now.Add(40*time.Second))
+	g.Expect(gate.describe()).To(Equal("waiting for the shoot nodes to remain ready until 2024-01-01T10:01:10Z"))
+	gate.observe(readyNodes, now.Add(70*time.Second))
+	g.Expect(gate.isOpen(now.Add(70 * time.Second))).To(BeTrue())
+}
+
+func TestNodeReadinessGateShouldRestartSettlePeriod(t *testing.T) {
+	g := NewWithT(t)
+	gate := newNodeReadinessGate(createNodeReadinessGateConfig(1, time.Minute))
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	readyNodes := createNodesWithReadiness(corev1.ConditionTrue, corev1.ConditionTrue)
+
+	gate.observe(readyNodes, now)
+	gate.observe(createNodesWithReadiness(corev1.ConditionTrue, corev1.ConditionFalse), now.Add(30*time.Second))
+	gate.observe(readyNodes, now.Add(40*time.Second))
+	g.Expect(gate.isOpen(now.Add(70*time.Second))).To(BeFalse(), "the settle period should restart once the ready node fraction is not met")
+	g.Expect(gate.isOpen(now.Add(100 * time.Second))).To(BeTrue())
+
+	gate.reset()
+	g.Expect(gate.isOpen(now.Add(100*time.Second))).To(BeFalse(), "the gate should be closed after a reset")
+	gate.observe(nil, now.Add(100*time.Second))
+	g.Expect(gate.isOpen(now.Add(200*time.Second))).To(BeFalse(), "the gate should be closed if there are no nodes")
+}
+
+func createNodeReadinessGateConfig(readyNodeFraction float64, settlePeriod time.Duration) *papi.NodeReadinessGate {
+	return &papi.NodeReadinessGate{
+		ReadyNodeFraction: pointer.Float64(readyNodeFraction),
+		SettlePeriod:      &metav1.Duration{Duration: settlePeriod},
+	}
+}
+
+func createNodesWithReadiness(readyStatuses ...corev1.ConditionStatus) []corev1.Node {
+	var nodes []corev1.Node
+	for i, status := range readyStatuses {
+		node := createNode(name(i))
+		node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
@@ -6,12 +6,16 @@ package prober
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 
 	papi "github.com/gardener/dependency-watchdog/api/prober"
 	dwdScaler "github.com/gardener/dependency-watchdog/internal/prober/scaler"
@@ -35,6 +39,11 @@ const (
 	// 		to renew the node lease.
 	expiryBufferFraction = 0.75
 	nodeLeaseNamespace   = "kube-node-lease"
+	// eventReasonMaxScaledDownDurationExceeded is the reason of the event which is recorded when the dependent resources are restored
+	// as they have been kept scaled down for longer than MaxScaledDownDuration.
+	eventReasonMaxScaledDownDurationExceeded = "MaxScaledDownDurationExceeded"
+	// eventReasonScaleDownHeldByBlastRadiusGuard is the reason of the event which is recorded when a scale-down is held back by the blast radius guard.
+	eventReasonScaleDownHeldByBlastRadiusGuard = "ScaleDownHeldByBlastRadiusGuard"
 )
 
 // Prober represents a probe to the Kube ApiServer of a shoot
@@ -43,17 +52,64 @@ type Prober struct {
 	config             *papi.Config
 	scaler             dwdScaler.Scaler
 	shootClientCreator ShootClientCreator
-	backOff            *time.Timer
-	ctx                context.Context
-	cancelFn           context.CancelFunc
-	l                  logr.Logger
+	// backOffUntil is the time until which the prober backs off as the API server of the shoot has throttled its requests.
+	backOffUntil       time.Time
+	inFlightScaleFlow  *scaleFlow
+	lastScaleOperation *scaleOperation
+	nodeReadinessGate  *nodeReadinessGate
+	// recordedScaleUpGate is the state of the node readiness gate which has last been recorded on the dependent resources.
+	recordedScaleUpGate string
+	// scaledDownSince is the time at which the first scale-down has been triggered since the lease probe has last succeeded.
+	scaledDownSince *time.Time
+	// scaleDownOverridden is true if the dependent resources have been restored as MaxScaledDownDuration has been exceeded.
+	// No further scale-down is done until the lease probe succeeds again.
+	scaleDownOverridden bool
+	// scaleDownGuard if set, is asked before each scale-down. It is shared by all probers.
+	scaleDownGuard ScaleDownGuard
+	// scaleDownHeld is true if the last scale-down has been held back by the scaleDownGuard.
+	scaleDownHeld bool
+	// startupRampDelay is the additional delay of the first probe if the prober has been created during the startup ramp.
+	startupRampDelay time.Duration
+	// eventRecorder if set, records events about the prober on eventObject, which is typically the Cluster of the shoot.
+	eventRecorder record.EventRecorder
+	eventObject   runtime.Object
+	ctx           context.Context
+	cancelFn      context.CancelFunc
+	l             logr.Logger
+}
+
+type proberOption func(p *Prober)
+
+// WithStartupRamp configures the prober to spread its first probe according to the given startup ramp, if it is created during the ramp.
+func WithStartupRamp(ramp *StartupRamp) proberOption {
+	return func(p *Prober) {
+		if ramp != nil {
+			p.startupRampDelay = ramp.delay(p.namespace, time.Now())
+		}
+	}
+}
+
+// WithScaleDownGuard configures the prober with a guard which is asked before each scale-down.
+func WithScaleDownGuard(guard ScaleDownGuard) proberOption {
+	return func(p *Prober) {
+		p.scaleDownGuard = guard
+	}
+}
+
+// WithEventRecorder configures the prober to record events about decisions which need the attention of an operator on the given object,
+// which is typically the Cluster of the shoot.
+func WithEventRecorder(recorder record.EventRecorder, object runtime.Object) proberOption {
+	return func(p *Prober) {
+		p.eventRecorder = recorder
+		p.eventObject = object
+	}
 }
 
 // NewProber creates a new Prober
-func NewProber(parentCtx context.Context, namespace string, config *papi.Config, scaler dwdScaler.Scaler, shootClientCreator ShootClientCreator, logger logr.Logger) *Prober {
+func NewProber(parentCtx context.Context, namespace string, config *papi.Config, scaler dwdScaler.Scaler, shootClientCreator ShootClientCreator, logger logr.Logger, options ...proberOption) *Prober {
 	pLogger := logger.WithValues("shootNamespace", namespace)
 	ctx, cancelFn := context.WithCancel(parentCtx)
-	return &Prober{
+	p := &Prober{
 		namespace:          namespace,
 		config:             config,
 		scaler:             scaler,
@@ -62,6 +118,13 @@ func NewProber(parentCtx context.Context, namespace string, config *papi.Config,
 		cancelFn:           cancelFn,
 		l:                  pLogger,
 	}
+	if config.NodeReadinessGate != nil {
+		p.nodeReadinessGate = newNodeReadinessGate(config.NodeReadinessGate)
+	}
+	for _, opt := range options {
+		opt(p)
+	}
+	return p
 }
 
 // Close closes a probe
@@ -80,9 +143,18 @@ func (p *Prober) IsClosed() bool {
 }
 
 // Run starts a probe which will run with a configured interval and jitter.
+// Once the prober is closed it waits for any in-flight scale flow to exit before returning.
 func (p *Prober) Run() {
-	_ = util.SleepWithContext(p.ctx, p.config.InitialDelay.Duration)
+	_ = util.SleepWithContext(p.ctx, p.firstProbeDelay())
 	wait.JitterUntilWithContext(p.ctx, p.probe, p.config.ProbeInterval.Duration, *p.config.BackoffJitterFactor, true)
+	p.waitForInFlightScaleFlow()
+}
+
+// firstProbeDelay returns the delay of the first probe. In addition to the initial delay, the first probes of different probers are
+// spread deterministically across the probe interval by hashing their namespace, or across the startup ramp if the prober has been
+// created during the startup ramp. This avoids that all probers which are created at once fire their first probes together.
+func (p *Prober) firstProbeDelay() time.Duration {
+	return p.config.InitialDelay.Duration + max(spreadOffset(p.namespace, p.config.ProbeInterval.Duration), p.startupRampDelay)
 }
 
 // GetConfig returns the probe config for the prober.
@@ -91,7 +163,7 @@ func (p *Prober) GetConfig() *papi.Config {
 }
 
 func (p *Prober) probe(ctx context.Context) {
-	p.backOffIfNeeded()
+	p.backOffIfNeeded(ctx)
 	shootClient, err := p.setupProbeClient(ctx, p.namespace, p.config.KubeConfigSecretName)
 	if err != nil {
 		p.l.Error(err, "Failed to create shoot client using the KubeConfig secret, ignoring error, probe will be re-attempted")
@@ -104,7 +176,7 @@ func (p *Prober) probe(ctx context.Context) {
 	}
 	p.l.Info("API server probe is successful, will conduct node lease probe")
 
-	candidateNodeLeases, err := p.probeNodeLeases(shootClient)
+	nodes, candidateNodeLeases, err := p.probeNodeLeases(shootClient)
 	if err != nil {
 		return
 	}
@@ -112,30 +184,236 @@ func (p *Prober) probe(ctx context.Context) {
 		p.l.Info("No owned node leases are present in the cluster, skipping scaling operation")
 		return
 	}
-	if p.shouldPerformScaleUp(candidateNodeLeases) {
+	expiredNodeLeaseCount := p.countExpiredNodeLeases(candidateNodeLeases)
+	if p.shouldPerformScaleUp(expiredNodeLeaseCount, len(candidateNodeLeases)) {
 		p.l.Info("Lease probe succeeded, performing scale up operation if required")
-		if err = p.scaler.ScaleUp(ctx); err != nil {
-			p.l.Error(err, "Failed to scale up resources")
+		if p.nodeReadinessGate != nil {
+			p.nodeReadinessGate.observe(nodes, time.Now())
+		}
+		p.scaledDownSince = nil
+		p.scaleDownOverridden = false
+		p.scaleDownHeld = false
+		if p.scaleDownGuard != nil {
+			p.scaleDownGuard.Release(p.namespace)
 		}
+		p.triggerScaleFlow(ctx, scaleUpOperation, "")
 	} else {
 		p.l.Info("Lease probe failed, performing scale down operation if required")
-		if err = p.scaler.ScaleDown(ctx); err != nil {
-			p.l.Error(err, "Failed to scale down resources")
+		if p.nodeReadinessGate != nil {
+			p.nodeReadinessGate.reset()
 		}
+		if p.isMaxScaledDownDurationExceeded() {
+			p.overrideScaleDown(ctx)
+			return
+		}
+		if p.scaleDownGuard != nil && !p.scaleDownGuard.AdmitScaleDown(p.namespace) {
+			p.l.Info("Scale down is held back by the blast radius guard as too many probers of the seed want to scale down, which indicates a seed-side fault")
+			// the scale-down is re-attempted with every probe, the event is only recorded once it is held back.
+			if !p.scaleDownHeld {
+				p.recordEvent(corev1.EventTypeWarning, eventReasonScaleDownHeldByBlastRadiusGuard,
+					"Scale down of dependent resources is held back by the blast radius guard as too many probers of the seed want to scale down, which indicates a seed-side fault")
+			}
+			p.scaleDownHeld = true
+			return
+		}
+		p.scaleDownHeld = false
+		reason := fmt.Sprintf("node lease probe failed: %d of %d node leases have expired which is at or above the configured node lease failure fraction of %.2f",
+			expiredNodeLeaseCount, len(candidateNodeLeases), *p.config.NodeLeaseFailureFraction)
+		p.triggerScaleFlow(ctx, scaleDownOperation, reason)
+	}
+}
+
+// triggerScaleFlow starts the scale flow for the given operation asynchronously. A scale flow can take a long time to complete
+// (initial delays, waiting for replicas), so it runs with its own cancellable context which allows the prober to react to a
+// change in the probe outcome. If a flow for the same operation is already in progress then it is left untouched. If a flow
+// for the opposite operation is in progress then it is cancelled, and the new flow is only started by a later probe once the
+// cancelled flow has exited. This ensures that there are never two flows concurrently scaling the same resources, without
+// blocking the probe until the cancelled flow has exited. The reason is recorded in the scaling history
+// of the scaled resources.
+func (p *Prober) triggerScaleFlow(ctx context.Context, op scaleOperation, reason string) {
+	if !p.clearInFlightScaleFlow(op) {
+		return
+	}
+	if op == scaleUpOperation && (!p.isScaleUpRequired(ctx) || !p.isNodeReadinessGatePassed(ctx)) {
 		return
 	}
+	if op == scaleDownOperation {
+		// a scale-down flow could scale down resources even if it fails or is cancelled later, therefore it is recorded as soon as it starts.
+		p.lastScaleOperation = &op
+		// a scale-down records a new scaling history on the dependent resources which carries no scale-up gate.
+		p.recordedScaleUpGate = ""
+		if p.scaledDownSince == nil {
+			now := time.Now()
+			p.scaledDownSince = &now
+		}
+	}
+	p.inFlightScaleFlow = p.startScaleFlow(ctx, op, reason)
+}
+
+// clearInFlightScaleFlow clears a completed in-flight scale flow and cancels an in-flight scale flow for the opposite operation.
+// It returns false if a scale flow is still in progress, in which case no new flow should be started. A cancelled scale flow is
+// not waited for, it is cleared by a later probe once it has exited.
+func (p *Prober) clearInFlightScaleFlow(op scaleOperation) bool {
+	sf := p.inFlightScaleFlow
+	if sf == nil {
+		return true
+	}
+	if !sf.isDone() {
+		if sf.operation == op {
+			p.l.Info("Scale flow is already in progress, skipping", "operation", op)
+			return false
+		}
+		if !sf.cancelled {
+			p.l.Info("Probe outcome has changed, cancelling in-flight scale flow", "inFlightOperation", sf.operation, "operation", op)
+			sf.cancelFn()
+			sf.cancelled = true
+		} else {
+			p.l.Info("Cancelled scale flow has not exited yet, skipping", "inFlightOperation", sf.operation, "operation", op)
+		}
+		return false
+	}
+	p.recordScaleFlowCompletion(sf)
+	p.inFlightScaleFlow = nil
+	return true
+}
+
+// isMaxScaledDownDurationExceeded checks if the dependent resources have been kept scaled down for longer than MaxScaledDownDuration.
+func (p *Prober) isMaxScaledDownDurationExceeded() bool {
+	if p.config.MaxScaledDownDuration == nil || p.scaledDownSince == nil {
+		return false
+	}
+	return time.Since(*p.scaledDownSince) >= p.config.MaxScaledDownDuration.Duration
+}
+
+// overrideScaleDown restores the dependent resources although the lease probe still fails, as they have been kept scaled down
+// for longer than MaxScaledDownDuration. The dependent resources are only restored once, no further scale-down is done until the
+// lease probe succeeds again. The reason for the override is recorded in the scaling history of each restored resource.
+func (p *Prober) overrideScaleDown(ctx context.Context) {
+	if !p.clearInFlightScaleFlow(scaleUpOperation) {
+		return
+	}
+	// if restoring the dependent resources has failed, then it is re-attempted.
+	if p.scaleDownOverridden && p.lastScaleOperation != nil && *p.lastScaleOperation == scaleUpOperation {
+		p.l.Info("Skipping scale down as the dependent resources have been restored after the maximum scaled down duration was exceeded",
+			"scaledDownSince", p.scaledDownSince, "maxScaledDownDuration", p.config.MaxScaledDownDuration.Duration)
+		return
+	}
+	reason := fmt.Sprintf("maximum scaled down duration of %s exceeded: restored although the node lease probe still fails since %s",
+		p.config.MaxScaledDownDuration.Duration, p.scaledDownSince.UTC().Format(time.RFC3339))
+	p.l.Error(nil, "Dependent resources have been kept scaled down for longer than the maximum scaled down duration, restoring them although the lease probe still fails",
+		"scaledDownSince", p.scaledDownSince, "maxScaledDownDuration", p.config.MaxScaledDownDuration.Duration)
+	// a failed restore is re-attempted with the next probe, the override is only reported once.
+	if !p.scaleDownOverridden {
+		scaleDownOverridesTotal.Inc()
+		p.recordEvent(corev1.EventTypeWarning, eventReasonMaxScaledDownDurationExceeded, "Restoring dependent resources: %s", reason)
+	}
+	p.scaleDownOverridden = true
+	p.inFlightScaleFlow = p.startScaleFlow(ctx, scaleUpOperation, reason)
+}
+
+// recordEvent records an event on the event object of the prober. It is a no-op if no event recorder is configured.
+func (p *Prober) recordEvent(eventType, reason, messageFmt string, args ...interface{}) {
+	if p.eventRecorder == nil || p.eventObject == nil {
+		return
+	}
+	p.eventRecorder.Eventf(p.eventObject, eventType, reason, messageFmt, args...)
+}
+
+// isScaleUpRequired checks if a scale-up flow needs to run. A scale-up is only required if a scale-down was previously triggered
+// by this prober. If the prober has no record of a previous scale operation (e.g. after DWD has been restarted), then
+// the dependent resources are checked for having been scaled down by DWD.
+func (p *Prober) isScaleUpRequired(ctx context.Context) bool {
+	if p.lastScaleOperation != nil {
+		if *p.lastScaleOperation == scaleUpOperation {
+			p.l.V(4).Info("Skipping scale up as no scale down has been done since the last scale up")
+			return false
+		}
+		return true
+	}
+	scaledDown, err := p.scaler.IsScaledDown(ctx)
+	if err != nil {
+		p.l.Error(err, "Failed to determine if any dependent resource has been scaled down, will attempt scale up")
+		return true
+	}
+	// the outcome is recorded, so that the dependent resources are not checked again while a scale-up is held back or retried.
+	op := scaleDownOperation
+	if !scaledDown {
+		p.l.Info("No dependent resource has been scaled down, skipping scale up")
+		op = scaleUpOperation
+	}
+	p.lastScaleOperation = &op
+	return scaledDown
+}
+
+// isNodeReadinessGatePassed checks if the node readiness gate, if configured, allows a scale-up. While the gate holds back the
+// scale-up its state is recorded in the scaling history of the dependent resources, so that it is visible why they are not scaled up yet.
+func (p *Prober) isNodeReadinessGatePassed(ctx context.Context) bool {
+	gate := p.nodeReadinessGate
+	if gate == nil {
+		return true
+	}
+	if gate.isOpen(time.Now()) {
+		p.l.Info("Node readiness gate has passed", "readyNodes", gate.readyNodeCount, "nodes", gate.nodeCount)
+		return true
+	}
+	description := gate.describe()
+	p.l.Info("Node readiness gate is holding back scale up", "readyNodes", gate.readyNodeCount, "nodes", gate.nodeCount,
+		"readyNodeFraction", *gate.config.ReadyNodeFraction, "settlePeriod", gate.config.SettlePeriod.Duration, "state", description)
+	if description != p.recordedScaleUpGate {
+		if err := p.scaler.RecordScaleUpGate(ctx, description); err != nil {
+			p.l.Error(err, "Failed to record the state of the node readiness gate on the dependent resources")
+		} else {
+			p.recordedScaleUpGate = description
+		}
+	}
+	return false
+}
+
+func (p *Prober) startScaleFlow(ctx context.Context, op scaleOperation, reason string) *scaleFlow {
+	flowCtx, cancelFn := context.WithCancel(ctx)
+	sf := &scaleFlow{
+		operation: op,
+		cancelFn:  cancelFn,
+		done:      make(chan struct{}),
+	}
+	go func() {
+		defer close(sf.done)
+		defer cancelFn()
+		if op == scaleUpOperation {
+			sf.err = p.scaler.ScaleUp(flowCtx, reason)
+		} else {
+			sf.err = p.scaler.ScaleDown(flowCtx, reason)
+		}
+		if sf.err != nil {
+			p.l.Error(sf.err, "Failed to scale resources", "operation", op)
+		}
+	}()
+	return sf
+}
+
+// recordScaleFlowCompletion records a successfully completed scale-up flow as the last scale operation.
+func (p *Prober) recordScaleFlowCompletion(sf *scaleFlow) {
+	if sf.operation == scaleUpOperation && sf.err == nil {
+		op := scaleUpOperation
+		p.lastScaleOperation = &op
+	}
 }
 
 // shouldPerformScaleUp returns true if the ratio of expired node leases to valid node leases is less than
 // the NodeLeaseFailureFraction set in the prober config
-func (p *Prober) shouldPerformScaleUp(candidateNodeLeases []coordinationv1.Lease) bool {
-	var expiredNodeLeaseCount float64
+func (p *Prober) shouldPerformScaleUp(expiredNodeLeaseCount, candidateNodeLeaseCount int) bool {
+	return float64(expiredNodeLeaseCount)/float64(candidateNodeLeaseCount) < *p.config.NodeLeaseFailureFraction
+}
+
+// countExpiredNodeLeases returns the number of expired node leases amongst the candidateNodeLeases.
+func (p *Prober) countExpiredNodeLeases(candidateNodeLeases []coordinationv1.Lease) int {
+	var expiredNodeLeaseCount int
 	for _, lease := range candidateNodeLeases {
 		if p.isLeaseExpired(lease) {
 			expiredNodeLeaseCount++
 		}
 	}
-	return expiredNodeLeaseCount/float64(len(candidateNodeLeases)) < *p.config.NodeLeaseFailureFraction
+	return expiredNodeLeaseCount
 }
 
 func (p *Prober) setupProbeClient(ctx context.Context, namespace string, kubeConfigSecretName string) (kubernetes.Interface, error) {
@@ -152,12 +430,13 @@ func (p *Prober) probeAPIServer(shootClient kubernetes.Interface) error {
 	return err
 }
 
-func (p *Prober) probeNodeLeases(shootClient kubernetes.Interface) ([]coordinationv1.Lease, error) {
+// probeNodeLeases returns the shoot nodes and the leases which belong to them.
+func (p *Prober) probeNodeLeases(shootClient kubernetes.Interface) ([]corev1.Node, []coordinationv1.Lease, error) {
 	nodes, err := shootClient.CoreV1().Nodes().List(p.ctx, metav1.ListOptions{})
 	if err != nil {
 		p.setBackOffIfThrottlingError(err)
 		p.l.Error(err, "Failed to list nodes, will retry probe")
-		return nil, err
+		return nil, nil, err
 	}
 
 	nodeNames := sets.New[string]()
@@ -169,7 +448,7 @@ func (p *Prober) probeNodeLeases(shootClient kubernetes.Interface) ([]coordinati
 	if err != nil {
 		p.setBackOffIfThrottlingError(err)
 		p.l.Error(err, "Failed to list leases, will retry probe")
-		return nil, err
+		return nil, nil, err
 	}
 
 	var filteredLeases []coordinationv1.Lease
@@ -180,7 +459,7 @@ func (p *Prober) probeNodeLeases(shootClient kubernetes.Interface) ([]coordinati
 		}
 	}
 
-	return filteredLeases, err
+	return nodes.Items, filteredLeases, err
 }
 
 func (p *Prober) isLeaseExpired(lease coordinationv1.Lease) bool {
@@ -189,11 +468,49 @@ func (p *Prober) isLeaseExpired(lease coordinationv1.Lease) bool {
 	return util.EqualOrBeforeNow(expiryTime)
 }
 
-func (p *Prober) backOffIfNeeded() {
-	if p.backOff != nil {
-		<-p.backOff.C
-		p.backOff.Stop()
-		p.backOff = nil
+// scaleOperation is the scaling operation that the prober triggers based on the outcome of a lease probe.
+type scaleOperation uint8
+
+const (
+	// scaleUpOperation restores the dependent resources once the lease probe succeeds.
+	scaleUpOperation scaleOperation = iota
+	// scaleDownOperation scales down the dependent resources once the lease probe fails.
+	scaleDownOperation
+)
+
+func (o scaleOperation) String() string {
+	if o == scaleUpOperation {
+		return "scale-up"
+	}
+	return "scale-down"
+}
+
+// scaleFlow captures a scale flow which has been started asynchronously by the prober.
+type scaleFlow struct {
+	operation scaleOperation
+	cancelFn  context.CancelFunc
+	// cancelled is true once the scale flow has been cancelled as the probe outcome has changed.
+	cancelled bool
+	// err is the error returned by the scale flow. It should only be read once done is closed.
+	err error
+	// done is closed once the scale flow has exited.
+	done chan struct{}
+}
+
+func (sf *scaleFlow) isDone() bool {
+	select {
+	case <-sf.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// backOffIfNeeded waits until the back-off of the prober has ended. When the prober is run by the Scheduler, the probe is only
+// scheduled once the back-off has ended, so that the worker is not blocked.
+func (p *Prober) backOffIfNeeded(ctx context.Context) {
+	if wait := time.Until(p.backOffUntil); wait > 0 {
+		_ = util.SleepWithContext(ctx, wait)
 	}
 }
 
@@ -213,8 +530,22 @@ func (p *Prober) setBackOffIfThrottlingError(err error) {
 }
 
 func (p *Prober) resetBackoff(d time.Duration) {
-	if p.backOff != nil {
-		p.backOff.Stop()
+	p.backOffUntil = time.Now().Add(d)
+}
+
+// nextProbeAt returns the time at which the next probe is due after the probe interval with its jitter, or once the back-off of
+// the prober has ended if that is later.
+func (p *Prober) nextProbeAt() time.Time {
+	next := time.Now().Add(wait.Jitter(p.config.ProbeInterval.Duration, *p.config.BackoffJitterFactor))
+	if p.backOffUntil.After(next) {
+		return p.backOffUntil
+	}
+	return next
+}
+
+// waitForInFlightScaleFlow waits until the in-flight scale flow, if any, has exited.
+func (p *Prober) waitForInFlightScaleFlow() {
+	if p.inFlightScaleFlow != nil {
+		<-p.inFlightScaleFlow.done
 	}
-	p.backOff = time.NewTimer(d)
 }
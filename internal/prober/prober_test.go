@@ -11,6 +11,7 @@ import (
 	"errors"
 	"math"
 	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -22,6 +23,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 
 	papi "github.com/gardener/dependency-watchdog/api/prober"
@@ -38,6 +40,7 @@ var (
 	proberTestLogger         = logr.Discard()
 	testProbeTimeout         = metav1.Duration{Duration: 3 * time.Millisecond}
 	testProbeInterval        = metav1.Duration{Duration: 3 * time.Millisecond}
+	testProberRunDuration    = 10 * testProbeInterval.Duration
 	expiredLeaseRenewTime    = metav1.NewMicroTime(time.Now().Add(-(2 * time.Minute)))
 	nonExpiredLeaseRenewTime = metav1.NewMicroTime(time.Now().Add(-time.Second))
 )
@@ -81,7 +84,7 @@ func TestAPIServerProbeFailure(t *testing.T) {
 		t.Run(entry.name, func(t *testing.T) {
 			mocks := createAndInitializeMocks(t, entry)
 			config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
-			createAndRunProber(t, testProbeInterval.Duration, config, mocks)
+			createAndRunProber(t, testProberRunDuration, config, mocks)
 		})
 	}
 }
@@ -99,7 +102,7 @@ func TestSuccessfulProbesShouldRunScaleUp(t *testing.T) {
 		t.Run(entry.name, func(t *testing.T) {
 			mocks := createAndInitializeMocks(t, entry)
 			config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
-			createAndRunProber(t, testProbeInterval.Duration, config, mocks)
+			createAndRunProber(t, testProberRunDuration, config, mocks)
 		})
 	}
 }
@@ -117,7 +120,7 @@ func TestLeaseProbeShouldNotConsiderUnrelatedLeases(t *testing.T) {
 		t.Run(entry.name, func(t *testing.T) {
 			mocks := createAndInitializeMocks(t, entry)
 			config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
-			createAndRunProber(t, testProbeInterval.Duration, config, mocks)
+			createAndRunProber(t, testProberRunDuration, config, mocks)
 		})
 	}
 }
@@ -135,7 +138,7 @@ func TestLeaseProbeFailureShouldRunScaleDown(t *testing.T) {
 		t.Run(entry.name, func(t *testing.T) {
 			mocks := createAndInitializeMocks(t, entry)
 			config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: time.Minute}, 0.2)
-			createAndRunProber(t, testProbeInterval.Duration, config, mocks)
+			createAndRunProber(t, testProberRunDuration, config, mocks)
 		})
 	}
 }
@@ -155,7 +158,7 @@ func TestLeaseProbeListCallFailureShouldSkipScaling(t *testing.T) {
 		t.Run(entry.name, func(t *testing.T) {
 			mocks := createAndInitializeMocks(t, entry)
 			config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
-			createAndRunProber(t, testProbeInterval.Duration, config, mocks)
+			createAndRunProber(t, testProberRunDuration, config, mocks)
 		})
 	}
 }
@@ -168,7 +171,7 @@ func TestAPIServerProbeShouldNotRunIfClientNotCreated(t *testing.T) {
 	}
 	mocks := createAndInitializeMocks(t, entry)
 	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
-	createAndRunProber(t, testProbeInterval.Duration, config, mocks)
+	createAndRunProber(t, testProberRunDuration, config, mocks)
 }
 
 func TestScalingShouldNotHappenIfNoOwnedLeasesPresent(t *testing.T) {
@@ -179,7 +182,250 @@ func TestScalingShouldNotHappenIfNoOwnedLeasesPresent(t *testing.T) {
 	}
 	mocks := createAndInitializeMocks(t, entry)
 	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
-	createAndRunProber(t, testProbeInterval.Duration, config, mocks)
+	createAndRunProber(t, testProberRunDuration, config, mocks)
+}
+
+func TestChangeInProbeOutcomeShouldCancelInFlightScaleFlow(t *testing.T) {
+	g := NewWithT(t)
+	expiredLeaseList := createNodeLeases([]metav1.MicroTime{expiredLeaseRenewTime, expiredLeaseRenewTime, expiredLeaseRenewTime})
+	nonExpiredLeaseList := createNodeLeases([]metav1.MicroTime{nonExpiredLeaseRenewTime, nonExpiredLeaseRenewTime, nonExpiredLeaseRenewTime})
+	mocks := createMocks(t)
+	initializeShootClientMocks(mocks, createNodes(len(expiredLeaseList.Items)))
+	gomock.InOrder(
+		mocks.lease.EXPECT().List(gomock.Any(), gomock.Any()).Return(expiredLeaseList, nil).Times(1),
+		mocks.lease.EXPECT().List(gomock.Any(), gomock.Any()).Return(nonExpiredLeaseList, nil).AnyTimes(),
+	)
+	var scaleDownCancelled, scaleUpCount atomic.Int32
+	mocks.scaler.EXPECT().ScaleDown(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ string) error {
+		<-ctx.Done()
+		scaleDownCancelled.Add(1)
+		return ctx.Err()
+	}).Times(1)
+	mocks.scaler.EXPECT().ScaleUp(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ string) error {
+		g.Expect(scaleDownCancelled.Load()).To(Equal(int32(1)), "scale up should only start once the in-flight scale down has exited")
+		scaleUpCount.Add(1)
+		return nil
+	}).MinTimes(1)
+
+	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+	p := NewProber(context.Background(), "default", config, mocks.scaler, mocks.shootClientCreator, proberTestLogger)
+	go p.Run()
+	g.Eventually(scaleUpCount.Load).Should(BeNumerically(">=", 1))
+	p.Close()
+}
+
+func TestCancelledScaleFlowShouldBeClearedByLaterProbeOnceExited(t *testing.T) {
+	g := NewWithT(t)
+	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+	p := NewProber(context.Background(), "default", config, createMocks(t).scaler, nil, proberTestLogger)
+	defer p.Close()
+	var cancelCount atomic.Int32
+	sf := &scaleFlow{operation: scaleDownOperation, cancelFn: func() { cancelCount.Add(1) }, done: make(chan struct{})}
+	p.inFlightScaleFlow = sf
+
+	g.Expect(p.clearInFlightScaleFlow(scaleUpOperation)).To(BeFalse(), "a new scale flow should not be started before the cancelled one has exited")
+	g.Expect(p.clearInFlightScaleFlow(scaleUpOperation)).To(BeFalse())
+	g.Expect(cancelCount.Load()).To(Equal(int32(1)), "the in-flight scale flow should only be cancelled once")
+	g.Expect(p.inFlightScaleFlow).To(Equal(sf))
+
+	close(sf.done)
+	g.Expect(p.clearInFlightScaleFlow(scaleUpOperation)).To(BeTrue())
+	g.Expect(p.inFlightScaleFlow).To(BeNil())
+}
+
+func TestInFlightScaleFlowShouldNotBeRestartedForSameProbeOutcome(t *testing.T) {
+	g := NewWithT(t)
+	expiredLeaseList := createNodeLeases([]metav1.MicroTime{expiredLeaseRenewTime, expiredLeaseRenewTime, expiredLeaseRenewTime})
+	mocks := createMocks(t)
+	initializeShootClientMocks(mocks, createNodes(len(expiredLeaseList.Items)))
+	leaseProbeCount := expectLeaseListCalls(mocks, expiredLeaseList)
+	mocks.scaler.EXPECT().ScaleDown(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}).Times(1)
+
+	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+	p := NewProber(context.Background(), "default", config, mocks.scaler, mocks.shootClientCreator, proberTestLogger)
+	go p.Run()
+	g.Eventually(leaseProbeCount.Load).Should(BeNumerically(">=", 3))
+	p.Close()
+}
+
+func TestScaleUpShouldBeSkippedIfNothingHasBeenScaledDown(t *testing.T) {
+	g := NewWithT(t)
+	nonExpiredLeaseList := createNodeLeases([]metav1.MicroTime{nonExpiredLeaseRenewTime, nonExpiredLeaseRenewTime, nonExpiredLeaseRenewTime})
+	mocks := createMocks(t)
+	initializeShootClientMocks(mocks, createNodes(len(nonExpiredLeaseList.Items)))
+	leaseProbeCount := expectLeaseListCalls(mocks, nonExpiredLeaseList)
+	mocks.scaler.EXPECT().IsScaledDown(gomock.Any()).Return(false, nil).Times(1)
+	mocks.scaler.EXPECT().ScaleUp(gomock.Any(), gomock.Any()).Times(0)
+
+	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+	p := NewProber(context.Background(), "default", config, mocks.scaler, mocks.shootClientCreator, proberTestLogger)
+	go p.Run()
+	g.Eventually(leaseProbeCount.Load).Should(BeNumerically(">=", 3))
+	p.Close()
+}
+
+func TestScaleUpShouldNotBeRepeatedAfterSuccessfulScaleUp(t *testing.T) {
+	g := NewWithT(t)
+	nonExpiredLeaseList := createNodeLeases([]metav1.MicroTime{nonExpiredLeaseRenewTime, nonExpiredLeaseRenewTime, nonExpiredLeaseRenewTime})
+	mocks := createMocks(t)
+	initializeShootClientMocks(mocks, createNodes(len(nonExpiredLeaseList.Items)))
+	leaseProbeCount := expectLeaseListCalls(mocks, nonExpiredLeaseList)
+	mocks.scaler.EXPECT().IsScaledDown(gomock.Any()).Return(true, nil).Times(1)
+	mocks.scaler.EXPECT().ScaleUp(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+	p := NewProber(context.Background(), "default", config, mocks.scaler, mocks.shootClientCreator, proberTestLogger)
+	go p.Run()
+	g.Eventually(leaseProbeCount.Load).Should(BeNumerically(">=", 5))
+	p.Close()
+}
+
+func TestScaleUpShouldRunAfterScaleDownWithoutCheckingResources(t *testing.T) {
+	g := NewWithT(t)
+	expiredLeaseList := createNodeLeases([]metav1.MicroTime{expiredLeaseRenewTime, expiredLeaseRenewTime, expiredLeaseRenewTime})
+	nonExpiredLeaseList := createNodeLeases([]metav1.MicroTime{nonExpiredLeaseRenewTime, nonExpiredLeaseRenewTime, nonExpiredLeaseRenewTime})
+	mocks := createMocks(t)
+	initializeShootClientMocks(mocks, createNodes(len(expiredLeaseList.Items)))
+	gomock.InOrder(
+		mocks.lease.EXPECT().List(gomock.Any(), gomock.Any()).Return(expiredLeaseList, nil).Times(1),
+		mocks.lease.EXPECT().List(gomock.Any(), gomock.Any()).Return(nonExpiredLeaseList, nil).AnyTimes(),
+	)
+	var scaleUpCount atomic.Int32
+	mocks.scaler.EXPECT().IsScaledDown(gomock.Any()).Times(0)
+	mocks.scaler.EXPECT().ScaleDown(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mocks.scaler.EXPECT().ScaleUp(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ string) error {
+		scaleUpCount.Add(1)
+		return nil
+	}).Times(1)
+
+	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+	p := NewProber(context.Background(), "default", config, mocks.scaler, mocks.shootClientCreator, proberTestLogger)
+	go p.Run()
+	g.Eventually(scaleUpCount.Load).Should(Equal(int32(1)))
+	p.Close()
+}
+
+func TestScaleDownShouldBeTriggeredWithReason(t *testing.T) {
+	g := NewWithT(t)
+	leaseList := createNodeLeases([]metav1.MicroTime{nonExpiredLeaseRenewTime, expiredLeaseRenewTime, expiredLeaseRenewTime, expiredLeaseRenewTime})
+	mocks := createMocks(t)
+	initializeShootClientMocks(mocks, createNodes(len(leaseList.Items)))
+	expectLeaseListCalls(mocks, leaseList)
+	reasonCh := make(chan string, 1)
+	mocks.scaler.EXPECT().ScaleDown(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, reason string) error {
+		select {
+		case reasonCh <- reason:
+		default:
+		}
+		return nil
+	}).MinTimes(1)
+
+	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+	p := NewProber(context.Background(), "default", config, mocks.scaler, mocks.shootClientCreator, proberTestLogger)
+	go p.Run()
+	g.Eventually(reasonCh).Should(Receive(Equal("node lease probe failed: 3 of 4 node leases have expired which is at or above the configured node lease failure fraction of 0.60")))
+	p.Close()
+}
+
+func TestScaleUpShouldBeHeldBackByNodeReadinessGate(t *testing.T) {
+	g := NewWithT(t)
+	nonExpiredLeaseList := createNodeLeases([]metav1.MicroTime{nonExpiredLeaseRenewTime, nonExpiredLeaseRenewTime})
+	mocks := createMocks(t)
+	initializeShootClientMocks(mocks, &corev1.NodeList{Items: createNodesWithReadiness(corev1.ConditionTrue, corev1.ConditionFalse)})
+	leaseProbeCount := expectLeaseListCalls(mocks, nonExpiredLeaseList)
+	mocks.scaler.EXPECT().IsScaledDown(gomock.Any()).Return(true, nil).Times(1)
+	mocks.scaler.EXPECT().RecordScaleUpGate(gomock.Any(), "waiting for at least 1.00 of the shoot nodes to be ready").Return(nil).Times(1)
+	mocks.scaler.EXPECT().ScaleUp(gomock.Any(), gomock.Any()).Times(0)
+
+	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+	config.NodeReadinessGate = createNodeReadinessGateConfig(1, 0)
+	p := NewProber(context.Background(), "default", config, mocks.scaler, mocks.shootClientCreator, proberTestLogger)
+	go p.Run()
+	g.Eventually(leaseProbeCount.Load).Should(BeNumerically(">=", 5))
+	p.Close()
+}
+
+func TestScaleUpShouldRunOncePassingNodeReadinessGate(t *testing.T) {
+	g := NewWithT(t)
+	nonExpiredLeaseList := createNodeLeases([]metav1.MicroTime{nonExpiredLeaseRenewTime, nonExpiredLeaseRenewTime})
+	mocks := createMocks(t)
+	initializeShootClientMocks(mocks, &corev1.NodeList{Items: createNodesWithReadiness(corev1.ConditionTrue, corev1.ConditionTrue)})
+	expectLeaseListCalls(mocks, nonExpiredLeaseList)
+	var scaleUpCount atomic.Int32
+	mocks.scaler.EXPECT().IsScaledDown(gomock.Any()).Return(true, nil).Times(1)
+	mocks.scaler.EXPECT().RecordScaleUpGate(gomock.Any(), gomock.Any()).Return(nil).MaxTimes(1)
+	mocks.scaler.EXPECT().ScaleUp(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ string) error {
+		scaleUpCount.Add(1)
+		return nil
+	}).Times(1)
+
+	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+	config.NodeReadinessGate = createNodeReadinessGateConfig(1, 10*time.Millisecond)
+	p := NewProber(context.Background(), "default", config, mocks.scaler, mocks.shootClientCreator, proberTestLogger)
+	go p.Run()
+	g.Eventually(scaleUpCount.Load).Should(Equal(int32(1)))
+	p.Close()
+}
+
+func TestDependentResourcesShouldBeRestoredOnceMaxScaledDownDurationIsExceeded(t *testing.T) {
+	g := NewWithT(t)
+	expiredLeaseList := createNodeLeases([]metav1.MicroTime{expiredLeaseRenewTime, expiredLeaseRenewTime, expiredLeaseRenewTime})
+	mocks := createMocks(t)
+	initializeShootClientMocks(mocks, createNodes(len(expiredLeaseList.Items)))
+	leaseProbeCount := expectLeaseListCalls(mocks, expiredLeaseList)
+	var scaleDownAfterScaleUpCount, scaleUpCount atomic.Int32
+	mocks.scaler.EXPECT().ScaleDown(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ string) error {
+		if scaleUpCount.Load() > 0 {
+			scaleDownAfterScaleUpCount.Add(1)
+		}
+		return nil
+	}).MinTimes(1)
+	reasonCh := make(chan string, 1)
+	mocks.scaler.EXPECT().ScaleUp(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, reason string) error {
+		scaleUpCount.Add(1)
+		reasonCh <- reason
+		return nil
+	}).Times(1)
+
+	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+	config.MaxScaledDownDuration = &metav1.Duration{Duration: 20 * time.Millisecond}
+	recorder := record.NewFakeRecorder(10)
+	overridesBefore := counterValue(scaleDownOverridesTotal)
+	p := NewProber(context.Background(), "default", config, mocks.scaler, mocks.shootClientCreator, proberTestLogger,
+		WithEventRecorder(recorder, &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "default"}}))
+	go p.Run()
+	g.Eventually(reasonCh).Should(Receive(HavePrefix("maximum scaled down duration of 20ms exceeded")))
+	probeCountAfterScaleUp := leaseProbeCount.Load()
+	g.Eventually(leaseProbeCount.Load).Should(BeNumerically(">=", probeCountAfterScaleUp+3))
+	p.Close()
+	g.Expect(scaleDownAfterScaleUpCount.Load()).To(BeZero(), "no scale down should be done once the dependent resources have been restored")
+	g.Expect(recorder.Events).To(Receive(HavePrefix("Warning MaxScaledDownDurationExceeded Restoring dependent resources: maximum scaled down duration of 20ms exceeded")))
+	g.Expect(recorder.Events).ToNot(Receive(), "the override should only be reported once")
+	g.Expect(counterValue(scaleDownOverridesTotal) - overridesBefore).To(Equal(float64(1)))
+}
+
+func TestScaleDownShouldBeHeldBackByScaleDownGuard(t *testing.T) {
+	g := NewWithT(t)
+	expiredLeaseList := createNodeLeases([]metav1.MicroTime{expiredLeaseRenewTime, expiredLeaseRenewTime, expiredLeaseRenewTime})
+	mocks := createMocks(t)
+	initializeShootClientMocks(mocks, createNodes(len(expiredLeaseList.Items)))
+	leaseProbeCount := expectLeaseListCalls(mocks, expiredLeaseList)
+	mocks.scaler.EXPECT().ScaleDown(gomock.Any(), gomock.Any()).Times(0)
+
+	guard := newBlastRadiusGuard(&papi.BlastRadiusGuard{Window: &metav1.Duration{Duration: time.Minute}, MaxScaleDowns: pointer.Int(1)}, proberTestLogger)
+	g.Expect(guard.AdmitScaleDown("other-shoot")).To(BeTrue())
+	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+	recorder := record.NewFakeRecorder(10)
+	p := NewProber(context.Background(), "default", config, mocks.scaler, mocks.shootClientCreator, proberTestLogger, WithScaleDownGuard(guard),
+		WithEventRecorder(recorder, &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "default"}}))
+	go p.Run()
+	g.Eventually(leaseProbeCount.Load).Should(BeNumerically(">=", 3))
+	p.Close()
+	g.Expect(recorder.Events).To(Receive(HavePrefix("Warning ScaleDownHeldByBlastRadiusGuard ")))
+	g.Expect(recorder.Events).ToNot(Receive(), "the held back scale down should only be reported once")
 }
 
 func createAndRunProber(t *testing.T, duration time.Duration, config *papi.Config, interfaces probeTestMocks) {
@@ -187,27 +433,37 @@ func createAndRunProber(t *testing.T, duration time.Duration, config *papi.Confi
 	p := NewProber(context.Background(), "default", config, interfaces.scaler, interfaces.shootClientCreator, proberTestLogger)
 	g.Expect(p.IsClosed()).To(BeFalse())
 
-	runProber(p, duration)
+	// the first probe is spread across the probe interval, therefore the prober has to run for the given duration in addition.
+	runProber(p, p.firstProbeDelay()+duration)
 	g.Expect(p.IsClosed()).To(BeTrue())
 }
 
+// runProber runs the prober for the given duration and waits until it has exited, including its in-flight scale flow.
 func runProber(p *Prober, d time.Duration) {
 	exitAfter := time.NewTimer(d)
-	go p.Run()
-	for {
-		select {
-		case <-exitAfter.C:
-			p.Close()
-			return
-		case <-p.ctx.Done():
-			return
-		}
+	defer exitAfter.Stop()
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		p.Run()
+	}()
+	select {
+	case <-exitAfter.C:
+		p.Close()
+	case <-p.ctx.Done():
 	}
+	<-exited
 }
 
 func createAndInitializeMocks(t *testing.T, testCase probeTestCase) probeTestMocks {
+	mocks := createMocks(t)
+	initializeMocks(mocks, testCase)
+	return mocks
+}
+
+func createMocks(t *testing.T) probeTestMocks {
 	ctrl := gomock.NewController(t)
-	mocks := probeTestMocks{
+	return probeTestMocks{
 		scaler:             mockscaler.NewMockScaler(ctrl),
 		shootClientCreator: mockprober.NewMockShootClientCreator(ctrl),
 		kubernetes:         mockinterface.NewMockInterface(ctrl),
@@ -217,8 +473,26 @@ func createAndInitializeMocks(t *testing.T, testCase probeTestCase) probeTestMoc
 		node:               mockcorev1.NewMockNodeInterface(ctrl),
 		lease:              mockcoordinationv1.NewMockLeaseInterface(ctrl),
 	}
-	initializeMocks(mocks, testCase)
-	return mocks
+}
+
+func expectLeaseListCalls(mocks probeTestMocks, leaseList *coordinationv1.LeaseList) *atomic.Int32 {
+	var count atomic.Int32
+	mocks.lease.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ metav1.ListOptions) (*coordinationv1.LeaseList, error) {
+		count.Add(1)
+		return leaseList, nil
+	}).AnyTimes()
+	return &count
+}
+
+func initializeShootClientMocks(mocks probeTestMocks, nodeList *corev1.NodeList) {
+	mocks.shootClientCreator.EXPECT().CreateClient(gomock.Any(), proberTestLogger, gomock.Any(), gomock.Any(), gomock.Any()).Return(mocks.kubernetes, nil).AnyTimes()
+	mocks.kubernetes.EXPECT().Discovery().Return(mocks.discovery).AnyTimes()
+	mocks.kubernetes.EXPECT().CoreV1().Return(mocks.coreV1).AnyTimes()
+	mocks.kubernetes.EXPECT().CoordinationV1().Return(mocks.coordinationV1).AnyTimes()
+	mocks.coreV1.EXPECT().Nodes().Return(mocks.node).AnyTimes()
+	mocks.coordinationV1.EXPECT().Leases(nodeLeaseNamespace).Return(mocks.lease).AnyTimes()
+	mocks.node.EXPECT().List(gomock.Any(), gomock.Any()).Return(nodeList, nil).AnyTimes()
+	mocks.discovery.EXPECT().ServerVersion().Return(nil, nil).AnyTimes()
 }
 
 func initializeMocks(mocks probeTestMocks, testCase probeTestCase) {
@@ -231,8 +505,9 @@ func initializeMocks(mocks probeTestMocks, testCase probeTestCase) {
 	mocks.node.EXPECT().List(gomock.Any(), gomock.Any()).Return(testCase.nodeList, testCase.nodeListError).AnyTimes()
 	mocks.lease.EXPECT().List(gomock.Any(), gomock.Any()).Return(testCase.leaseList, testCase.leaseListError).AnyTimes()
 	mocks.discovery.EXPECT().ServerVersion().Return(nil, testCase.discoveryError).AnyTimes()
-	mocks.scaler.EXPECT().ScaleUp(gomock.Any()).Return(testCase.scaleUpError).MaxTimes(testCase.maxScaleUpCount).MinTimes(testCase.minScaleUpCount)
-	mocks.scaler.EXPECT().ScaleDown(gomock.Any()).Return(testCase.scaleDownError).MaxTimes(testCase.maxScaleDownCount).MinTimes(testCase.minScaleDownCount)
+	mocks.scaler.EXPECT().IsScaledDown(gomock.Any()).Return(true, nil).AnyTimes()
+	mocks.scaler.EXPECT().ScaleUp(gomock.Any(), gomock.Any()).Return(testCase.scaleUpError).MaxTimes(testCase.maxScaleUpCount).MinTimes(testCase.minScaleUpCount)
+	mocks.scaler.EXPECT().ScaleDown(gomock.Any(), gomock.Any()).Return(testCase.scaleDownError).MaxTimes(testCase.maxScaleDownCount).MinTimes(testCase.minScaleDownCount)
 }
 
 func createConfig(probeInterval metav1.Duration, initialDelay metav1.Duration, kcmNodeMonitorGraceDuration metav1.Duration, backoffJitterFactor float64) *papi.Config {
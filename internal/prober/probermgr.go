@@ -4,11 +4,19 @@
 
 package prober
 
-import "sync"
+import (
+	"sync"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/util"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
 
 // Manager is the convenience interface to manage lifecycle of probers.
 type Manager interface {
-	// Register registers the given prober with the manager. It should return false if prober is already registered.
+	// Register registers the given prober with the manager. It should return false if prober is already registered or if any namespace other
+	// than its own namespace in which it scales dependent resources is already claimed by another registered prober.
 	Register(prober Prober) bool
 	// Unregister closes the prober and removes it from the manager. It should return false if prober is not registered with the manager.
 	Unregister(key string) bool
@@ -16,18 +24,39 @@ type Manager interface {
 	GetProber(key string) (Prober, bool)
 	// GetAllProbers returns a slice of all the probers registered with the manager.
 	GetAllProbers() []Prober
+	// GetScaleDownGuard returns the guard which is shared by all probers to decide if they are allowed to scale down. It returns nil if no guard is configured.
+	GetScaleDownGuard() ScaleDownGuard
+}
+
+type managerOption func(pm *manager)
+
+// WithBlastRadiusGuard configures the manager with a guard which holds back scale-downs across all probers once too many probers want to scale down.
+func WithBlastRadiusGuard(config *papi.BlastRadiusGuard, logger logr.Logger) managerOption {
+	return func(pm *manager) {
+		if config != nil {
+			pm.blastRadiusGuard = newBlastRadiusGuard(config, logger)
+		}
+	}
 }
 
 // NewManager creates a new manager to manage probers.
-func NewManager() Manager {
-	return &manager{
-		probers: make(map[string]Prober),
+func NewManager(options ...managerOption) Manager {
+	pm := &manager{
+		probers:         make(map[string]Prober),
+		namespaceClaims: make(map[string]string),
 	}
+	for _, opt := range options {
+		opt(pm)
+	}
+	return pm
 }
 
 type manager struct {
 	sync.Mutex
 	probers map[string]Prober
+	// namespaceClaims maps each namespace outside the shoot namespaces in which dependent resources are scaled to the key of the prober which claims it.
+	namespaceClaims  map[string]string
+	blastRadiusGuard *blastRadiusGuard
 }
 
 func (pm *manager) Unregister(key string) bool {
@@ -35,7 +64,15 @@ func (pm *manager) Unregister(key string) bool {
 	defer pm.Unlock()
 	if probe, ok := pm.probers[key]; ok {
 		delete(pm.probers, key)
+		for namespace := range getClaimedNamespaces(probe) {
+			if pm.namespaceClaims[namespace] == key {
+				delete(pm.namespaceClaims, namespace)
+			}
+		}
 		probe.Close()
+		if pm.blastRadiusGuard != nil {
+			pm.blastRadiusGuard.unregister(key)
+		}
 		return true
 	}
 	return false
@@ -45,11 +82,24 @@ func (pm *manager) Register(prober Prober) bool {
 	pm.Lock()
 	defer pm.Unlock()
 	key := createKey(prober)
-	if _, ok := pm.probers[key]; !ok {
-		pm.probers[key] = prober
-		return true
+	if _, ok := pm.probers[key]; ok {
+		return false
 	}
-	return false
+	claimedNamespaces := getClaimedNamespaces(prober)
+	for namespace := range claimedNamespaces {
+		if claimant, ok := pm.namespaceClaims[namespace]; ok && claimant != key {
+			prober.l.Error(newSharedNamespaceError(namespace), "Namespace of dependent resources is already claimed by the prober of another shoot, prober will not be registered", "claimedBy", claimant)
+			return false
+		}
+	}
+	for namespace := range claimedNamespaces {
+		pm.namespaceClaims[namespace] = key
+	}
+	pm.probers[key] = prober
+	if pm.blastRadiusGuard != nil {
+		pm.blastRadiusGuard.register(key)
+	}
+	return true
 }
 
 func (pm *manager) GetProber(key string) (Prober, bool) {
@@ -65,6 +115,31 @@ func (pm *manager) GetAllProbers() []Prober {
 	return probers
 }
 
+func (pm *manager) GetScaleDownGuard() ScaleDownGuard {
+	if pm.blastRadiusGuard == nil {
+		return nil
+	}
+	return pm.blastRadiusGuard
+}
+
+// getClaimedNamespaces returns the rendered namespaces of the dependent resources of the prober which differ from the namespace of the prober.
+// Two probers which scale dependent resources in the same such namespace would fight over their replicas and annotations. Namespaces
+// which are shared by all shoots are already rejected when the configuration is loaded, see validateNamespace.
+func getClaimedNamespaces(prober Prober) sets.Set[string] {
+	namespaces := sets.New[string]()
+	if prober.config == nil {
+		return namespaces
+	}
+	for _, resInfo := range prober.config.DependentResourceInfos {
+		namespace, err := util.RenderNamespace(resInfo.Namespace, prober.namespace)
+		if err != nil || namespace == prober.namespace {
+			continue
+		}
+		namespaces.Insert(namespace)
+	}
+	return namespaces
+}
+
 func createKey(prober Prober) string {
 	return prober.namespace // check if this would be sufficient
 }
@@ -9,11 +9,13 @@ package prober
 import (
 	"context"
 	"testing"
+	"time"
 
 	papi "github.com/gardener/dependency-watchdog/api/prober"
 
 	"github.com/go-logr/logr"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const proberMgrTestNamespace = "default"
@@ -96,3 +98,43 @@ func TestUnregisterNonExistingProberShouldNotFail(t *testing.T) {
 	t.Log("De-registering a non existing prober did not fail")
 
 }
+
+func TestScaleDownGuardShouldOnlyBeReturnedIfConfigured(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(NewManager().GetScaleDownGuard()).To(BeNil())
+	g.Expect(NewManager(WithBlastRadiusGuard(nil, pmLogger)).GetScaleDownGuard()).To(BeNil())
+
+	one := 1
+	mgr := NewManager(WithBlastRadiusGuard(&papi.BlastRadiusGuard{Window: &metav1.Duration{Duration: time.Minute}, MaxScaleDowns: &one}, pmLogger))
+	guard := mgr.GetScaleDownGuard()
+	g.Expect(guard).ToNot(BeNil())
+
+	p1 := NewProber(context.Background(), "shoot-1", &papi.Config{}, nil, nil, pmLogger)
+	p2 := NewProber(context.Background(), "shoot-2", &papi.Config{}, nil, nil, pmLogger)
+	g.Expect(mgr.Register(*p1)).To(BeTrue())
+	g.Expect(mgr.Register(*p2)).To(BeTrue())
+	g.Expect(guard.AdmitScaleDown("shoot-1")).To(BeTrue())
+	g.Expect(guard.AdmitScaleDown("shoot-2")).To(BeFalse(), "the guard should count the scale downs of all probers registered with the manager")
+	g.Expect(mgr.Unregister("shoot-1")).To(BeTrue())
+	g.Expect(guard.AdmitScaleDown("shoot-2")).To(BeTrue(), "unregistering a prober should remove its scale down request from the guard")
+	g.Expect(mgr.Unregister("shoot-2")).To(BeTrue())
+}
+
+func TestProberShouldNotBeRegisteredIfNamespaceOfDependentResourcesIsClaimedByAnotherProber(t *testing.T) {
+	g := NewWithT(t)
+	mgr, tearDownTest := setupMgrTest(t)
+	defer tearDownTest(mgr)
+
+	newConfig := func(namespace string) *papi.Config {
+		return &papi.Config{DependentResourceInfos: []papi.DependentResourceInfo{{Namespace: namespace}}}
+	}
+	p1 := NewProber(context.Background(), "shoot--bingo--one", newConfig("{{ slice .ShootNamespace 0 11 }}-shared"), nil, nil, pmLogger)
+	p2 := NewProber(context.Background(), "shoot--bingo--two", newConfig("{{ slice .ShootNamespace 0 11 }}-shared"), nil, nil, pmLogger)
+	p3 := NewProber(context.Background(), "shoot--bingo--three", newConfig("{{ .ShootNamespace }}-extension"), nil, nil, pmLogger)
+	g.Expect(mgr.Register(*p1)).To(BeTrue())
+	g.Expect(mgr.Register(*p2)).To(BeFalse(), "mgr.Register should reject a prober whose dependent resources are in a namespace claimed by another prober")
+	g.Expect(mgr.Register(*p3)).To(BeTrue(), "mgr.Register should register a prober whose namespaces are not claimed")
+
+	g.Expect(mgr.Unregister(p1.namespace)).To(BeTrue())
+	g.Expect(mgr.Register(*p2)).To(BeTrue(), "unregistering a prober should release the namespaces claimed by it")
+}
@@ -23,28 +23,28 @@ const (
 )
 
 type flowCreator interface {
-	createFlow(name string, namespace string, opType operation) *scaleFlow
+	createFlow(name string, opType operation) *scaleFlow
 }
 
 type creator struct {
 	client                 client.Client
-	scaler                 scalev1.ScaleInterface
+	scalesGetter           scalev1.ScalesGetter
 	logger                 logr.Logger
 	options                *scalerOptions
 	dependentResourceInfos []papi.DependentResourceInfo
 }
 
-func newFlowCreator(client client.Client, scaler scalev1.ScaleInterface, logger logr.Logger, options *scalerOptions, dependentResourceInfos []papi.DependentResourceInfo) flowCreator {
+func newFlowCreator(client client.Client, scalesGetter scalev1.ScalesGetter, logger logr.Logger, options *scalerOptions, dependentResourceInfos []papi.DependentResourceInfo) flowCreator {
 	return &creator{
 		client:                 client,
-		scaler:                 scaler,
+		scalesGetter:           scalesGetter,
 		logger:                 logger,
 		options:                options,
 		dependentResourceInfos: dependentResourceInfos,
 	}
 }
 
-func (c *creator) createFlow(name string, namespace string, opType operation) *scaleFlow {
+func (c *creator) createFlow(name string, opType operation) *scaleFlow {
 	resourceInfos := createScalableResourceInfos(opType, c.dependentResourceInfos)
 	levels := sortAndGetUniqueLevels(resourceInfos)
 	orderedResourceInfos := collectResourceInfosByLevel(resourceInfos)
@@ -57,7 +57,7 @@ func (c *creator) createFlow(name string, namespace string, opType operation) *s
 			dependentTaskIDs := previousTaskIDs
 			taskID := g.Add(flow.Task{
 				Name:         createTaskName(resInfos, level),
-				Fn:           c.createScaleTaskFn(namespace, resInfos),
+				Fn:           c.createScaleTaskFn(resInfos),
 				Dependencies: dependentTaskIDs,
 			})
 			sf.addScaleStepInfo(taskID, dependentTaskIDs, previousLevelResourceInfos)
@@ -77,10 +77,10 @@ func (c *creator) createFlow(name string, namespace string, opType operation) *s
 // DependentResourceInfo passed to this function, it indicates that they all are at the same level indicating that these functions
 // should be invoked concurrently. In this case it will construct a flow.Parallel. If there is only one DependentResourceInfo passed
 // then it indicates that at a specific level there is only one DependentResourceInfo that needs to be scaled.
-func (c *creator) createScaleTaskFn(namespace string, resourceInfos []scalableResourceInfo) flow.TaskFn {
+func (c *creator) createScaleTaskFn(resourceInfos []scalableResourceInfo) flow.TaskFn {
 	taskFns := make([]flow.TaskFn, 0, len(resourceInfos))
 	for _, resourceInfo := range resourceInfos {
-		taskFn := c.doCreateTaskFn(namespace, resourceInfo)
+		taskFn := c.doCreateTaskFn(resourceInfo)
 		taskFns = append(taskFns, taskFn)
 	}
 	if len(taskFns) == 1 {
@@ -89,15 +89,15 @@ func (c *creator) createScaleTaskFn(namespace string, resourceInfos []scalableRe
 	return flow.Parallel(taskFns...)
 }
 
-func (c *creator) doCreateTaskFn(namespace string, resInfo scalableResourceInfo) flow.TaskFn {
+func (c *creator) doCreateTaskFn(resInfo scalableResourceInfo) flow.TaskFn {
 	return func(ctx context.Context) error {
 		var operation string
 		if resInfo.operation == scaleUp {
-			operation = fmt.Sprintf("scaleUp-resource-%s.%s", namespace, resInfo.ref.Name)
+			operation = fmt.Sprintf("scaleUp-resource-%s.%s", resInfo.namespace, resInfo.ref.Name)
 		} else {
-			operation = fmt.Sprintf("scaleDown-resource-%s.%s", namespace, resInfo.ref.Name)
+			operation = fmt.Sprintf("scaleDown-resource-%s.%s", resInfo.namespace, resInfo.ref.Name)
 		}
-		resScaler := newResourceScaler(c.client, c.scaler, c.logger, c.options, namespace, resInfo)
+		resScaler := newResourceScaler(c.client, c.scalesGetter.Scales(resInfo.namespace), c.logger, c.options, resInfo)
 		result := util.Retry(ctx, c.logger,
 			operation,
 			func() (interface{}, error) {
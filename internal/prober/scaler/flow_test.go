@@ -32,10 +32,9 @@ func TestCreateScaleUpSequentialFlow(t *testing.T) {
 
 	expectedScaleUpResNames := []string{kcmObjectRef.Name, mcmObjectRef.Name, caObjectRef.Name}
 	flowName := "testCreateSequentialFlow"
-	namespace := "test-sequential"
 
-	fc := newFlowCreator(&client.MockClient{}, &scale.MockScaleInterface{}, flowTestLogger, &scalerOptions{}, depResInfos)
-	f := fc.createFlow(flowName, namespace, scaleUp)
+	fc := newFlowCreator(&client.MockClient{}, &scale.MockScalesGetter{}, flowTestLogger, &scalerOptions{}, depResInfos)
+	f := fc.createFlow(flowName, scaleUp)
 	g.Expect(f.flowStepInfos).To(HaveLen(3))
 
 	previousDepTaskIDs := make([]flow.TaskID, 0, 3)
@@ -66,10 +65,9 @@ func TestCreateScaleDownSequentialAndConcurrentFlow(t *testing.T) {
 
 	expectedScaleUpResNames := map[int][]string{0: {mcmObjectRef.Name, caObjectRef.Name}, 1: {kcmObjectRef.Name}}
 	flowName := "testCreateSequentialAndConcurrentFlow"
-	namespace := "test-sequential-and-concurrent"
 
-	fc := newFlowCreator(&client.MockClient{}, &scale.MockScaleInterface{}, flowTestLogger, &scalerOptions{}, depResInfos)
-	f := fc.createFlow(flowName, namespace, scaleDown)
+	fc := newFlowCreator(&client.MockClient{}, &scale.MockScalesGetter{}, flowTestLogger, &scalerOptions{}, depResInfos)
+	f := fc.createFlow(flowName, scaleDown)
 	g.Expect(f.flowStepInfos).To(HaveLen(2))
 
 	previousDepTaskIDs := make([]flow.TaskID, 0, 3)
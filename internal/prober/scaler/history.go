@@ -0,0 +1,169 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+// scalingHistoryAnnotationKey is the key for an annotation whose value is a JSON encoded scalingHistory. It records the last
+// intervention of DWD for a resource and allows to distinguish an active scale-down from one that has already been restored.
+const scalingHistoryAnnotationKey = "dependency-watchdog.gardener.cloud/scaling-history"
+
+// scalingState is the state of the last intervention of DWD for a resource.
+type scalingState string
+
+const (
+	// scalingStateScaledDown indicates that the resource has been scaled down by DWD and has not been restored yet.
+	scalingStateScaledDown scalingState = "ScaledDown"
+	// scalingStateRestored indicates that the resource has been restored after it was scaled down by DWD.
+	scalingStateRestored scalingState = "Restored"
+)
+
+// scalingHistory is the record of the last scale-down of a resource by DWD and of its restoration.
+type scalingHistory struct {
+	// State is the state of the intervention.
+	State scalingState `json:"state"`
+	// OriginalReplicas are the spec.replicas of the resource prior to the scale-down.
+	OriginalReplicas int32 `json:"originalReplicas"`
+	// ScaledDownAt is the time at which DWD scaled down the resource.
+	ScaledDownAt metav1.Time `json:"scaledDownAt"`
+	// Reason describes why DWD scaled down the resource.
+	Reason string `json:"reason,omitempty"`
+	// RestoredAt is the time at which the resource was found to be restored after the scale-down.
+	RestoredAt *metav1.Time `json:"restoredAt,omitempty"`
+	// RestoredReplicas are the spec.replicas of the resource after it has been restored.
+	RestoredReplicas *int32 `json:"restoredReplicas,omitempty"`
+	// RestoreReason describes why the resource has been restored although the probe which scaled it down still fails. It is empty
+	// if the resource has been restored as the probe succeeds again.
+	RestoreReason string `json:"restoreReason,omitempty"`
+	// ScaleUpGate describes why the scale-up of the resource is held back although the probe which scaled it down succeeds again.
+	ScaleUpGate string `json:"scaleUpGate,omitempty"`
+	// PausedHPAs are the names of the HorizontalPodAutoscalers targeting the resource which have been paused by DWD before the scale-down.
+	// Only these are resumed once the resource is restored.
+	PausedHPAs []string `json:"pausedHPAs,omitempty"`
+}
+
+// scaleReasonKey is the context key under which the reason for a scale operation is passed to the resource scalers.
+type scaleReasonKey struct{}
+
+// withScaleReason returns a copy of ctx which carries the reason for a scale operation.
+func withScaleReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, scaleReasonKey{}, reason)
+}
+
+// getScaleReason returns the reason for a scale operation carried by ctx. It returns an empty string if no reason has been set.
+func getScaleReason(ctx context.Context) string {
+	reason, _ := ctx.Value(scaleReasonKey{}).(string)
+	return reason
+}
+
+// getScalingHistory returns the scalingHistory recorded in the annotations. It returns nil if no scalingHistory has been recorded.
+func getScalingHistory(annotations map[string]string) (*scalingHistory, error) {
+	historyStr, ok := annotations[scalingHistoryAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	history := &scalingHistory{}
+	if err := json.Unmarshal([]byte(historyStr), history); err != nil {
+		return nil, fmt.Errorf("unexpected and invalid value set for annotation: %s for resource, Err: %w", scalingHistoryAnnotationKey, err)
+	}
+	return history, nil
+}
+
+// getPausedHPAs returns the names of the HorizontalPodAutoscalers which have been paused by DWD for an active scalingHistory recorded in
+// the annotations. It returns nil if there is no active scalingHistory, as the HorizontalPodAutoscalers have then already been resumed.
+func getPausedHPAs(annotations map[string]string) ([]string, error) {
+	history, err := getScalingHistory(annotations)
+	if err != nil || history == nil || history.State != scalingStateScaledDown {
+		return nil, err
+	}
+	return history.PausedHPAs, nil
+}
+
+// createScaleDownAnnotationsPatch creates a merge patch which records the replicas prior to the scale-down and a new scalingHistory.
+// It also removes the allow-scale-up annotation, so that an override of the scale protection only applies to the scale-down it has been set for.
+func createScaleDownAnnotationsPatch(originalReplicas int32, reason string, scaledDownAt time.Time, pausedHPAs []string) ([]byte, error) {
+	history := scalingHistory{
+		State:            scalingStateScaledDown,
+		OriginalReplicas: originalReplicas,
+		ScaledDownAt:     metav1.NewTime(scaledDownAt),
+		Reason:           reason,
+		PausedHPAs:       pausedHPAs,
+	}
+	historyBytes, err := json.Marshal(history)
+	if err != nil {
+		return nil, err
+	}
+	return createAnnotationsPatch(map[string]*string{
+		ReplicasAnnotationKey:       pointer.String(strconv.Itoa(int(originalReplicas))),
+		scalingHistoryAnnotationKey: pointer.String(string(historyBytes)),
+		AllowScaleUpAnnotationKey:   nil,
+	})
+}
+
+// createRestoredAnnotationsPatch creates a merge patch which removes the replicas annotation and marks an active scalingHistory as restored
+// for the given reason. It returns nil if neither the replicas annotation nor an active scalingHistory is present in the annotations.
+func createRestoredAnnotationsPatch(annotations map[string]string, restoredReplicas int32, restoredAt time.Time, reason string) ([]byte, error) {
+	annotationsToPatch := make(map[string]*string)
+	if _, ok := annotations[ReplicasAnnotationKey]; ok {
+		annotationsToPatch[ReplicasAnnotationKey] = nil
+	}
+	history, err := getScalingHistory(annotations)
+	if err != nil {
+		return nil, err
+	}
+	if history != nil && history.State == scalingStateScaledDown {
+		history.State = scalingStateRestored
+		history.ScaleUpGate = ""
+		history.RestoreReason = reason
+		restoredAtTime := metav1.NewTime(restoredAt)
+		history.RestoredAt = &restoredAtTime
+		history.RestoredReplicas = &restoredReplicas
+		historyBytes, err := json.Marshal(history)
+		if err != nil {
+			return nil, err
+		}
+		annotationsToPatch[scalingHistoryAnnotationKey] = pointer.String(string(historyBytes))
+	}
+	if len(annotationsToPatch) == 0 {
+		return nil, nil
+	}
+	return createAnnotationsPatch(annotationsToPatch)
+}
+
+// createScaleUpGateAnnotationsPatch creates a merge patch which records the state of the scale-up gate in an active scalingHistory.
+// It returns nil if there is no active scalingHistory or if the same state has already been recorded.
+func createScaleUpGateAnnotationsPatch(annotations map[string]string, scaleUpGate string) ([]byte, error) {
+	history, err := getScalingHistory(annotations)
+	if err != nil {
+		return nil, err
+	}
+	if history == nil || history.State != scalingStateScaledDown || history.ScaleUpGate == scaleUpGate {
+		return nil, nil
+	}
+	history.ScaleUpGate = scaleUpGate
+	historyBytes, err := json.Marshal(history)
+	if err != nil {
+		return nil, err
+	}
+	return createAnnotationsPatch(map[string]*string{scalingHistoryAnnotationKey: pointer.String(string(historyBytes))})
+}
+
+// createAnnotationsPatch creates a merge patch for the annotations of a resource. An annotation with a nil value is removed.
+func createAnnotationsPatch(annotations map[string]*string) ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": annotations,
+		},
+	})
+}
@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package scaler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
+)
+
+func TestCreateScaleDownAnnotationsPatch(t *testing.T) {
+	g := NewWithT(t)
+	scaledDownAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	patchBytes, err := createScaleDownAnnotationsPatch(3, "node lease probe failed", scaledDownAt, []string{"kcm-hpa"})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	annotations := getPatchedAnnotations(g, patchBytes)
+	g.Expect(annotations[ReplicasAnnotationKey]).To(Equal(pointer.String("3")))
+	g.Expect(annotations).To(HaveKeyWithValue(AllowScaleUpAnnotationKey, BeNil()))
+	history, err := getScalingHistory(toAnnotations(annotations))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(history.State).To(Equal(scalingStateScaledDown))
+	g.Expect(history.OriginalReplicas).To(Equal(int32(3)))
+	g.Expect(history.ScaledDownAt.Time.Equal(scaledDownAt)).To(BeTrue())
+	g.Expect(history.Reason).To(Equal("node lease probe failed"))
+	g.Expect(history.RestoredAt).To(BeNil())
+	g.Expect(history.RestoredReplicas).To(BeNil())
+	g.Expect(history.PausedHPAs).To(Equal([]string{"kcm-hpa"}))
+}
+
+func TestGetPausedHPAsShouldOnlyBeReturnedForActiveScalingHistory(t *testing.T) {
+	g := NewWithT(t)
+	scaleDownPatchBytes, err := createScaleDownAnnotationsPatch(2, "node lease probe failed", time.Now(), []string{"kcm-hpa"})
+	g.Expect(err).ToNot(HaveOccurred())
+	scaledDownAnnotations := toAnnotations(getPatchedAnnotations(g, scaleDownPatchBytes))
+	g.Expect(getPausedHPAs(scaledDownAnnotations)).To(Equal([]string{"kcm-hpa"}))
+
+	restoredPatchBytes, err := createRestoredAnnotationsPatch(scaledDownAnnotations, 2, time.Now(), "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(getPausedHPAs(toAnnotations(getPatchedAnnotations(g, restoredPatchBytes)))).To(BeEmpty(), "the paused HorizontalPodAutoscalers of a restored resource have already been resumed")
+	g.Expect(getPausedHPAs(map[string]string{})).To(BeEmpty())
+}
+
+func TestCreateRestoredAnnotationsPatch(t *testing.T) {
+	g := NewWithT(t)
+	scaledDownAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	restoredAt := scaledDownAt.Add(5 * time.Minute)
+	scaleDownPatchBytes, err := createScaleDownAnnotationsPatch(2, "node lease probe failed", scaledDownAt, nil)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	patchBytes, err := createRestoredAnnotationsPatch(toAnnotations(getPatchedAnnotations(g, scaleDownPatchBytes)), 2, restoredAt, "maximum scaled down duration exceeded")
+	g.Expect(err).ToNot(HaveOccurred())
+	annotations := getPatchedAnnotations(g, patchBytes)
+	g.Expect(annotations).To(HaveKeyWithValue(ReplicasAnnotationKey, BeNil()), "the replicas annotation should be removed")
+	history, err := getScalingHistory(toAnnotations(annotations))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(history.State).To(Equal(scalingStateRestored))
+	g.Expect(history.OriginalReplicas).To(Equal(int32(2)))
+	g.Expect(history.ScaledDownAt.Time.Equal(scaledDownAt)).To(BeTrue())
+	g.Expect(history.RestoredAt.Time.Equal(restoredAt)).To(BeTrue())
+	g.Expect(*history.RestoredReplicas).To(Equal(int32(2)))
+	g.Expect(history.RestoreReason).To(Equal("maximum scaled down duration exceeded"))
+}
+
+func TestCreateRestoredAnnotationsPatchForCompletedOrMissingHistory(t *testing.T) {
+	g := NewWithT(t)
+	patchBytes, err := createRestoredAnnotationsPatch(map[string]string{"foo": "bar"}, 1, time.Now(), "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(patchBytes).To(BeNil(), "nothing should be patched if there is no scaling history and no replicas annotation")
+
+	restoredHistory := `{"state":"Restored","originalReplicas":2,"scaledDownAt":"2024-01-01T10:00:00Z","restoredAt":"2024-01-01T10:05:00Z","restoredReplicas":2}`
+	patchBytes, err = createRestoredAnnotationsPatch(map[string]string{scalingHistoryAnnotationKey: restoredHistory}, 3, time.Now(), "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(patchBytes).To(BeNil(), "a restored scaling history should not be changed")
+
+	patchBytes, err = createRestoredAnnotationsPatch(map[string]string{ReplicasAnnotationKey: "2"}, 2, time.Now(), "")
+	g.Expect(err).ToNot(HaveOccurred())
+	annotations := getPatchedAnnotations(g, patchBytes)
+	g.Expect(annotations).To(HaveLen(1))
+	g.Expect(annotations).To(HaveKeyWithValue(ReplicasAnnotationKey, BeNil()))
+
+	_, err = createRestoredAnnotationsPatch(map[string]string{scalingHistoryAnnotationKey: "invalid"}, 2, time.Now(), "")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestCreateScaleUpGateAnnotationsPatch(t *testing.T) {
+	g := NewWithT(t)
+	scaleDownPatchBytes, err := createScaleDownAnnotationsPatch(2, "node lease probe failed", time.Now(), nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	scaledDownAnnotations := toAnnotations(getPatchedAnnotations(g, scaleDownPatchBytes))
+
+	patchBytes, err := createScaleUpGateAnnotationsPatch(scaledDownAnnotations, "waiting for nodes to become ready")
+	g.Expect(err).ToNot(HaveOccurred())
+	gatedAnnotations := toAnnotations(getPatchedAnnotations(g, patchBytes))
+	gatedAnnotations[ReplicasAnnotationKey] = scaledDownAnnotations[ReplicasAnnotationKey]
+	history, err := getScalingHistory(gatedAnnotations)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(history.State).To(Equal(scalingStateScaledDown))
+	g.Expect(history.ScaleUpGate).To(Equal("waiting for nodes to become ready"))
+
+	patchBytes, err = createScaleUpGateAnnotationsPatch(gatedAnnotations, "waiting for nodes to become ready")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(patchBytes).To(BeNil(), "an already recorded scale-up gate should not be patched again")
+
+	patchBytes, err = createRestoredAnnotationsPatch(gatedAnnotations, 2, time.Now(), "")
+	g.Expect(err).ToNot(HaveOccurred())
+	history, err = getScalingHistory(toAnnotations(getPatchedAnnotations(g, patchBytes)))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(history.ScaleUpGate).To(BeEmpty(), "the scale-up gate should be cleared once the resource is restored")
+
+	patchBytes, err = createScaleUpGateAnnotationsPatch(map[string]string{"foo": "bar"}, "waiting for nodes to become ready")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(patchBytes).To(BeNil(), "nothing should be patched if there is no scaling history")
+}
+
+func TestScaleReasonIsCarriedByContext(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(getScaleReason(context.Background())).To(BeEmpty())
+	g.Expect(getScaleReason(withScaleReason(context.Background(), "node lease probe failed"))).To(Equal("node lease probe failed"))
+}
+
+func getPatchedAnnotations(g *WithT, patchBytes []byte) map[string]*string {
+	var patch struct {
+		Metadata struct {
+			Annotations map[string]*string `json:"annotations"`
+		} `json:"metadata"`
+	}
+	g.Expect(json.Unmarshal(patchBytes, &patch)).To(Succeed())
+	return patch.Metadata.Annotations
+}
+
+func toAnnotations(patchedAnnotations map[string]*string) map[string]string {
+	annotations := make(map[string]string, len(patchedAnnotations))
+	for k, v := range patchedAnnotations {
+		if v != nil {
+			annotations[k] = *v
+		}
+	}
+	return annotations
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/go-logr/logr"
+	multierr "github.com/hashicorp/go-multierror"
+)
+
+// scaleHookPhase denotes whether a scale hook is called before or after the dependent resources are scaled.
+type scaleHookPhase string
+
+const (
+	preScaleHookPhase  scaleHookPhase = "pre"
+	postScaleHookPhase scaleHookPhase = "post"
+)
+
+// scaleHookPayload is the JSON payload which is posted to the scale hooks.
+type scaleHookPayload struct {
+	// Namespace is the shoot namespace for which the dependent resources are scaled.
+	Namespace string `json:"namespace"`
+	// Operation is either scale-up or scale-down.
+	Operation string `json:"operation"`
+	// Phase is either pre or post.
+	Phase scaleHookPhase `json:"phase"`
+	// Reason describes why the dependent resources are scaled. It is empty for a scale-up unless the resources are restored while the probe still fails.
+	Reason string `json:"reason,omitempty"`
+	// Resources are the dependent resources which are scaled.
+	Resources []scaleHookResource `json:"resources"`
+	// Error is the error with which the scaling has failed. It is only set in the post phase.
+	Error string `json:"error,omitempty"`
+}
+
+// scaleHookResource identifies a dependent resource in the scaleHookPayload.
+type scaleHookResource struct {
+	Namespace  string `json:"namespace"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+func newScaleHookPayload(namespace string, opType operation, reason string, dependentResourceInfos []papi.DependentResourceInfo) scaleHookPayload {
+	resources := make([]scaleHookResource, 0, len(dependentResourceInfos))
+	for _, resInfo := range dependentResourceInfos {
+		resources = append(resources, scaleHookResource{
+			Namespace:  resInfo.Namespace,
+			APIVersion: resInfo.Ref.APIVersion,
+			Kind:       resInfo.Ref.Kind,
+			Name:       resInfo.Ref.Name,
+		})
+	}
+	return scaleHookPayload{
+		Namespace: namespace,
+		Operation: opType.String(),
+		Reason:    reason,
+		Resources: resources,
+	}
+}
+
+// scaleHookRunner calls the configured scale hooks.
+type scaleHookRunner struct {
+	httpClient *http.Client
+	hooks      []papi.ScaleHook
+	logger     logr.Logger
+}
+
+func newScaleHookRunner(hooks []papi.ScaleHook, logger logr.Logger) *scaleHookRunner {
+	return &scaleHookRunner{
+		httpClient: &http.Client{},
+		hooks:      hooks,
+		logger:     logger,
+	}
+}
+
+// run calls all scale hooks for the given phase. A failed call is only returned as an error if the failure policy of the hook is Block.
+func (r *scaleHookRunner) run(ctx context.Context, phase scaleHookPhase, payload scaleHookPayload) error {
+	if len(r.hooks) == 0 {
+		return nil
+	}
+	payload.Phase = phase
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	var blockingErr error
+	for _, hook := range r.hooks {
+		if err = r.call(ctx, hook, payloadBytes); err != nil {
+			if *hook.FailurePolicy == papi.ScaleHookFailurePolicyBlock {
+				blockingErr = multierr.Append(blockingErr, err)
+				continue
+			}
+			r.logger.Error(err, "Scale hook failed, ignoring error as per its failure policy", "hook", hook.Name, "operation", payload.Operation, "phase", phase)
+			continue
+		}
+		r.logger.V(4).Info("Scale hook succeeded", "hook", hook.Name, "operation", payload.Operation, "phase", phase)
+	}
+	return blockingErr
+}
+
+func (r *scaleHookRunner) call(ctx context.Context, hook papi.ScaleHook, payloadBytes []byte) error {
+	childCtx, cancelFn := context.WithTimeout(ctx, hook.Timeout.Duration)
+	defer cancelFn()
+	req, err := http.NewRequestWithContext(childCtx, http.MethodPost, hook.URL, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return fmt.Errorf("failed to create request for scale hook %s: %w", hook.Name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call scale hook %s: %w", hook.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("scale hook %s responded with unexpected status code %d", hook.Name, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package scaler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	mockscale "github.com/gardener/dependency-watchdog/internal/mock/client-go/scale"
+	mockclient "github.com/gardener/dependency-watchdog/internal/mock/controller-runtime/client"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const hookTestNamespace = "shoot--test--hooks"
+
+func TestScaleHooksShouldReceivePayload(t *testing.T) {
+	g := NewWithT(t)
+	requests := make(chan *http.Request, 1)
+	payloads := make(chan scaleHookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload scaleHookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		requests <- r
+		payloads <- payload
+	}))
+	defer server.Close()
+
+	resInfo := createTestDeploymentDependentResourceInfo(kcmObjectRef.Name, 0, 0, nil, nil, false)
+	resInfo.Namespace = hookTestNamespace
+	payload := newScaleHookPayload(hookTestNamespace, scaleDown, "node lease probe failed", []papi.DependentResourceInfo{resInfo})
+	r := newScaleHookRunner([]papi.ScaleHook{createScaleHook("alerting", server.URL, papi.ScaleHookFailurePolicyBlock)}, logr.Discard())
+
+	g.Expect(r.run(context.Background(), preScaleHookPhase, payload)).To(Succeed())
+	var req *http.Request
+	g.Expect(requests).To(Receive(&req))
+	g.Expect(req.Method).To(Equal(http.MethodPost))
+	g.Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+	var received scaleHookPayload
+	g.Expect(payloads).To(Receive(&received))
+	g.Expect(received.Namespace).To(Equal(hookTestNamespace))
+	g.Expect(received.Operation).To(Equal("scale-down"))
+	g.Expect(received.Phase).To(Equal(preScaleHookPhase))
+	g.Expect(received.Reason).To(Equal("node lease probe failed"))
+	g.Expect(received.Resources).To(ConsistOf(scaleHookResource{Namespace: hookTestNamespace, APIVersion: "apps/v1", Kind: "Deployment", Name: kcmObjectRef.Name}))
+}
+
+func TestScaleHookFailurePolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		title         string
+		failurePolicy papi.ScaleHookFailurePolicy
+		url           string
+		expectError   bool
+	}{
+		{"failed call with policy Ignore should not return an error", papi.ScaleHookFailurePolicyIgnore, server.URL, false},
+		{"failed call with policy Block should return an error", papi.ScaleHookFailurePolicyBlock, server.URL, true},
+		{"unreachable hook with policy Block should return an error", papi.ScaleHookFailurePolicyBlock, "http://127.0.0.1:1", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			g := NewWithT(t)
+			r := newScaleHookRunner([]papi.ScaleHook{createScaleHook("hook", test.url, test.failurePolicy)}, logr.Discard())
+			err := r.run(context.Background(), postScaleHookPhase, newScaleHookPayload(hookTestNamespace, scaleUp, "", nil))
+			if test.expectError {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestFailedBlockingPreScaleHookShouldSkipScaling(t *testing.T) {
+	g := NewWithT(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	// no calls are expected on the client or the scales getter as the dependent resources should not be scaled.
+	ds := NewScaler(hookTestNamespace, []papi.DependentResourceInfo{createTestDeploymentDependentResourceInfo(kcmObjectRef.Name, 0, 0, nil, nil, false)},
+		mockclient.NewMockClient(ctrl), mockscale.NewMockScalesGetter(ctrl), logr.Discard(),
+		WithScaleHooks([]papi.ScaleHook{createScaleHook("vpn", server.URL, papi.ScaleHookFailurePolicyBlock)}))
+	g.Expect(ds.ScaleDown(context.Background(), "node lease probe failed")).To(MatchError(ContainSubstring("pre-scale hooks failed")))
+}
+
+func TestScaleHooksShouldOnlyBeCalledOnceForRepeatedNoOpScaleDowns(t *testing.T) {
+	g := NewWithT(t)
+	var (
+		mu       sync.Mutex
+		payloads []scaleHookPayload
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload scaleHookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+	receivedPhases := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		phases := make([]string, 0, len(payloads))
+		for _, payload := range payloads {
+			phases = append(phases, payload.Operation+"/"+string(payload.Phase))
+		}
+		return phases
+	}
+
+	ctrl := gomock.NewController(t)
+	cl := mockclient.NewMockClient(ctrl)
+	// scaling of the dependent resource is ignored, therefore none of the scale flows changes its replicas.
+	cl.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&metav1.PartialObjectMetadata{})).DoAndReturn(
+		func(_ context.Context, _ client.ObjectKey, obj *metav1.PartialObjectMetadata, _ ...client.GetOption) error {
+			obj.Annotations = map[string]string{IgnoreScalingAnnotationKey: "true"}
+			return nil
+		}).AnyTimes()
+	scalesGetter := mockscale.NewMockScalesGetter(ctrl)
+	scalesGetter.EXPECT().Scales(hookTestNamespace).Return(mockscale.NewMockScaleInterface(ctrl)).AnyTimes()
+	zero := time.Duration(0)
+	ds := NewScaler(hookTestNamespace, []papi.DependentResourceInfo{createTestDeploymentDependentResourceInfo(kcmObjectRef.Name, 0, 0, nil, &zero, false)},
+		cl, scalesGetter, logr.Discard(),
+		WithScaleHooks([]papi.ScaleHook{createScaleHook("alerting", server.URL, papi.ScaleHookFailurePolicyBlock)}))
+
+	for i := 0; i < 3; i++ {
+		g.Expect(ds.ScaleDown(context.Background(), "node lease probe failed")).To(Succeed())
+	}
+	g.Expect(receivedPhases()).To(Equal([]string{"scale-down/pre", "scale-down/post"}), "scale hooks should only be called for the first of repeated no-op scale-downs")
+
+	g.Expect(ds.ScaleUp(context.Background(), "")).To(Succeed())
+	g.Expect(receivedPhases()).To(Equal([]string{"scale-down/pre", "scale-down/post", "scale-up/pre", "scale-up/post"}), "scale hooks should be called on an operation transition")
+}
+
+func TestChangesReplicas(t *testing.T) {
+	tests := []struct {
+		title    string
+		annot    map[string]string
+		replicas int64
+		opType   operation
+		expected bool
+	}{
+		{"scale-down of a resource with replicas should change replicas", nil, 2, scaleDown, true},
+		{"scale-down of a resource without replicas should not change replicas", nil, 0, scaleDown, false},
+		{"scale-up of a resource without replicas should change replicas", map[string]string{ReplicasAnnotationKey: "2"}, 0, scaleUp, true},
+		{"scale-up of a resource with replicas should not change replicas", nil, 1, scaleUp, false},
+		{"scale-down of a resource for which scaling is ignored should not change replicas", map[string]string{IgnoreScalingAnnotationKey: "true"}, 2, scaleDown, false},
+	}
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			g := NewWithT(t)
+			cl := mockclient.NewMockClient(gomock.NewController(t))
+			cl.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&metav1.PartialObjectMetadata{})).DoAndReturn(
+				func(_ context.Context, _ client.ObjectKey, obj *metav1.PartialObjectMetadata, _ ...client.GetOption) error {
+					obj.Annotations = test.annot
+					return nil
+				})
+			cl.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&unstructured.Unstructured{})).DoAndReturn(
+				func(_ context.Context, _ client.ObjectKey, obj *unstructured.Unstructured, _ ...client.GetOption) error {
+					return unstructured.SetNestedField(obj.Object, test.replicas, "spec", "replicas")
+				}).MaxTimes(1)
+			resInfo := createTestDeploymentDependentResourceInfo(kcmObjectRef.Name, 0, 0, nil, nil, false)
+			resInfo.Namespace = hookTestNamespace
+			ds := &scaleFlowRunner{client: cl}
+			changesReplicas, err := ds.changesReplicas(context.Background(), resInfo, test.opType)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(changesReplicas).To(Equal(test.expected))
+		})
+	}
+}
+
+func createScaleHook(name, url string, failurePolicy papi.ScaleHookFailurePolicy) papi.ScaleHook {
+	return papi.ScaleHook{
+		Name:          name,
+		URL:           url,
+		Timeout:       &metav1.Duration{Duration: time.Second},
+		FailurePolicy: &failurePolicy,
+	}
+}
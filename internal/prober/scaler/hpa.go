@@ -0,0 +1,163 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// hpaBehaviorAnnotationKey is the key for an annotation on a HorizontalPodAutoscaler which has been paused by DWD. Its value is the
+// JSON encoded spec.behavior of the HorizontalPodAutoscaler prior to pausing it, which is restored when the target resource is scaled up.
+const hpaBehaviorAnnotationKey = "dependency-watchdog.gardener.cloud/hpa-behavior"
+
+// hpaCoordinator pauses HorizontalPodAutoscalers which target a resource while the resource is scaled down by DWD and resumes them once
+// the resource has been scaled up again. Without it a HorizontalPodAutoscaler could scale up a resource which has been scaled down by DWD.
+type hpaCoordinator struct {
+	client    client.Client
+	logger    logr.Logger
+	namespace string
+	ref       *autoscalingv1.CrossVersionObjectReference
+}
+
+func newHPACoordinator(client client.Client, logger logr.Logger, namespace string, ref *autoscalingv1.CrossVersionObjectReference) *hpaCoordinator {
+	return &hpaCoordinator{
+		client:    client,
+		logger:    logger,
+		namespace: namespace,
+		ref:       ref,
+	}
+}
+
+// pause disables scaling for all HorizontalPodAutoscalers targeting the resource and records their prior behavior. A HorizontalPodAutoscaler
+// which has already been paused by DWD is left untouched, so that its original behavior is not overwritten. It returns the names of all
+// HorizontalPodAutoscalers targeting the resource which are paused by DWD, which are recorded in the scaling history of the resource.
+func (h *hpaCoordinator) pause(ctx context.Context) ([]string, error) {
+	hpas, err := h.getTargetingHPAs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pausedHPAs []string
+	for _, hpa := range hpas {
+		if _, ok := hpa.Annotations[hpaBehaviorAnnotationKey]; ok {
+			pausedHPAs = append(pausedHPAs, hpa.Name)
+			continue
+		}
+		behaviorBytes, err := json.Marshal(hpa.Spec.Behavior)
+		if err != nil {
+			return nil, err
+		}
+		if hpa.Annotations == nil {
+			hpa.Annotations = make(map[string]string)
+		}
+		hpa.Annotations[hpaBehaviorAnnotationKey] = string(behaviorBytes)
+		hpa.Spec.Behavior = createDisabledHPABehavior(hpa.Spec.Behavior)
+		if err = h.client.Update(ctx, &hpa); err != nil {
+			return nil, fmt.Errorf("failed to pause HorizontalPodAutoscaler %s/%s: %w", hpa.Namespace, hpa.Name, err)
+		}
+		pausedHPAs = append(pausedHPAs, hpa.Name)
+		h.logger.Info("Paused HorizontalPodAutoscaler targeting the resource", "hpa", hpa.Name)
+	}
+	return pausedHPAs, nil
+}
+
+// resume restores the behavior of the given HorizontalPodAutoscalers, which have been paused by DWD when the resource has been scaled
+// down. A HorizontalPodAutoscaler which no longer exists or which does not carry the behavior recorded by DWD is left untouched.
+func (h *hpaCoordinator) resume(ctx context.Context, hpaNames []string) error {
+	for _, hpaName := range hpaNames {
+		hpa := autoscalingv2.HorizontalPodAutoscaler{}
+		if err := h.client.Get(ctx, client.ObjectKey{Namespace: h.namespace, Name: hpaName}, &hpa); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			// coordination with HorizontalPodAutoscalers is best-effort, it should not prevent scaling of the resource.
+			if apierrors.IsForbidden(err) || meta.IsNoMatchError(err) {
+				h.logger.Info("Unable to get HorizontalPodAutoscaler, skipping coordination with HorizontalPodAutoscalers", "hpa", hpaName, "err", err.Error())
+				return nil
+			}
+			return fmt.Errorf("failed to get HorizontalPodAutoscaler %s/%s: %w", h.namespace, hpaName, err)
+		}
+		behaviorStr, ok := hpa.Annotations[hpaBehaviorAnnotationKey]
+		if !ok {
+			continue
+		}
+		var behavior *autoscalingv2.HorizontalPodAutoscalerBehavior
+		if err := json.Unmarshal([]byte(behaviorStr), &behavior); err != nil {
+			return fmt.Errorf("unexpected and invalid value set for annotation: %s for HorizontalPodAutoscaler %s/%s, Err: %w", hpaBehaviorAnnotationKey, hpa.Namespace, hpa.Name, err)
+		}
+		delete(hpa.Annotations, hpaBehaviorAnnotationKey)
+		hpa.Spec.Behavior = behavior
+		if err := h.client.Update(ctx, &hpa); err != nil {
+			return fmt.Errorf("failed to resume HorizontalPodAutoscaler %s/%s: %w", hpa.Namespace, hpa.Name, err)
+		}
+		h.logger.Info("Resumed HorizontalPodAutoscaler targeting the resource", "hpa", hpa.Name)
+	}
+	return nil
+}
+
+// getTargetingHPAs returns all HorizontalPodAutoscalers in the namespace of the resource whose scale target is the resource.
+func (h *hpaCoordinator) getTargetingHPAs(ctx context.Context) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
+	hpaList := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := h.client.List(ctx, hpaList, client.InNamespace(h.namespace)); err != nil {
+		// coordination with HorizontalPodAutoscalers is best-effort, it should not prevent scaling of the resource.
+		if apierrors.IsForbidden(err) || meta.IsNoMatchError(err) {
+			h.logger.Info("Unable to list HorizontalPodAutoscalers, skipping coordination with HorizontalPodAutoscalers", "err", err.Error())
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list HorizontalPodAutoscalers in namespace %s: %w", h.namespace, err)
+	}
+	var hpas []autoscalingv2.HorizontalPodAutoscaler
+	for _, hpa := range hpaList.Items {
+		if isHPATargetingResource(hpa, h.ref) {
+			hpas = append(hpas, hpa)
+		}
+	}
+	return hpas, nil
+}
+
+// isHPATargetingResource checks if the scale target of the HorizontalPodAutoscaler is the resource identified by ref.
+// Only the API group is compared as a resource can be referred to via any version of its API group.
+func isHPATargetingResource(hpa autoscalingv2.HorizontalPodAutoscaler, ref *autoscalingv1.CrossVersionObjectReference) bool {
+	target := hpa.Spec.ScaleTargetRef
+	if target.Kind != ref.Kind || target.Name != ref.Name {
+		return false
+	}
+	targetGV, err := schema.ParseGroupVersion(target.APIVersion)
+	if err != nil {
+		return false
+	}
+	refGV, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return false
+	}
+	return targetGV.Group == refGV.Group
+}
+
+// createDisabledHPABehavior returns a copy of the behavior where scaling up and down is disabled.
+func createDisabledHPABehavior(behavior *autoscalingv2.HorizontalPodAutoscalerBehavior) *autoscalingv2.HorizontalPodAutoscalerBehavior {
+	disabledBehavior := &autoscalingv2.HorizontalPodAutoscalerBehavior{}
+	if behavior != nil {
+		disabledBehavior = behavior.DeepCopy()
+	}
+	disabledPolicy := autoscalingv2.DisabledPolicySelect
+	if disabledBehavior.ScaleUp == nil {
+		disabledBehavior.ScaleUp = &autoscalingv2.HPAScalingRules{}
+	}
+	disabledBehavior.ScaleUp.SelectPolicy = &disabledPolicy
+	if disabledBehavior.ScaleDown == nil {
+		disabledBehavior.ScaleDown = &autoscalingv2.HPAScalingRules{}
+	}
+	disabledBehavior.ScaleDown.SelectPolicy = &disabledPolicy
+	return disabledBehavior
+}
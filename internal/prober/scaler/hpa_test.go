@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package scaler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mockclient "github.com/gardener/dependency-watchdog/internal/mock/controller-runtime/client"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const hpaTestNamespace = "test-hpa"
+
+func TestPauseAndResumeHPA(t *testing.T) {
+	g := NewWithT(t)
+	originalBehavior := &autoscalingv2.HorizontalPodAutoscalerBehavior{
+		ScaleUp: &autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: pointer.Int32(60)},
+	}
+	hpas := []autoscalingv2.HorizontalPodAutoscaler{
+		createHPA("kcm-hpa", kcmObjectRef.Kind, kcmObjectRef.Name, "apps/v1", originalBehavior),
+		createHPA("mcm-hpa", mcmObjectRef.Kind, mcmObjectRef.Name, "apps/v1", nil),
+	}
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+		list.(*autoscalingv2.HorizontalPodAutoscalerList).Items = hpas
+		return nil
+	}).Times(2)
+	mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{Namespace: hpaTestNamespace, Name: "kcm-hpa"}, gomock.Any()).DoAndReturn(func(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+		hpas[0].DeepCopyInto(obj.(*autoscalingv2.HorizontalPodAutoscaler))
+		return nil
+	}).Times(2)
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+		hpa := obj.(*autoscalingv2.HorizontalPodAutoscaler)
+		g.Expect(hpa.Name).To(Equal("kcm-hpa"), "only the HorizontalPodAutoscaler targeting the resource should be updated")
+		hpas[0] = *hpa.DeepCopy()
+		return nil
+	}).Times(2)
+
+	h := newHPACoordinator(mockClient, logr.Discard(), hpaTestNamespace, &kcmObjectRef)
+	pausedHPAs, err := h.pause(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(pausedHPAs).To(Equal([]string{"kcm-hpa"}))
+	g.Expect(hpas[0].Annotations).To(HaveKey(hpaBehaviorAnnotationKey))
+	g.Expect(*hpas[0].Spec.Behavior.ScaleUp.SelectPolicy).To(Equal(autoscalingv2.DisabledPolicySelect))
+	g.Expect(*hpas[0].Spec.Behavior.ScaleDown.SelectPolicy).To(Equal(autoscalingv2.DisabledPolicySelect))
+	g.Expect(*hpas[0].Spec.Behavior.ScaleUp.StabilizationWindowSeconds).To(Equal(int32(60)))
+	// pausing an already paused HorizontalPodAutoscaler should not overwrite its recorded behavior, but it should still be reported as paused
+	pausedHPAs, err = h.pause(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(pausedHPAs).To(Equal([]string{"kcm-hpa"}))
+
+	g.Expect(h.resume(context.Background(), pausedHPAs)).To(Succeed())
+	g.Expect(hpas[0].Annotations).ToNot(HaveKey(hpaBehaviorAnnotationKey))
+	g.Expect(hpas[0].Spec.Behavior).To(Equal(originalBehavior))
+	// resuming a HorizontalPodAutoscaler which is not paused should be a no-op
+	g.Expect(h.resume(context.Background(), pausedHPAs)).To(Succeed())
+}
+
+func TestResumeHPAWithoutPriorBehavior(t *testing.T) {
+	g := NewWithT(t)
+	hpa := createHPA("kcm-hpa", kcmObjectRef.Kind, kcmObjectRef.Name, "apps/v1", createDisabledHPABehavior(nil))
+	hpa.Annotations = map[string]string{hpaBehaviorAnnotationKey: "null"}
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+		hpa.DeepCopyInto(obj.(*autoscalingv2.HorizontalPodAutoscaler))
+		return nil
+	}).Times(1)
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+		updatedHPA := obj.(*autoscalingv2.HorizontalPodAutoscaler)
+		g.Expect(updatedHPA.Spec.Behavior).To(BeNil())
+		g.Expect(updatedHPA.Annotations).ToNot(HaveKey(hpaBehaviorAnnotationKey))
+		return nil
+	}).Times(1)
+
+	h := newHPACoordinator(mockClient, logr.Discard(), hpaTestNamespace, &kcmObjectRef)
+	g.Expect(h.resume(context.Background(), []string{"kcm-hpa"})).To(Succeed())
+}
+
+func TestResumeShouldOnlyResumeHPAsPausedByDWD(t *testing.T) {
+	g := NewWithT(t)
+	humanPausedHPA := createHPA("manual-hpa", kcmObjectRef.Kind, kcmObjectRef.Name, "apps/v1", createDisabledHPABehavior(nil))
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{Namespace: hpaTestNamespace, Name: "manual-hpa"}, gomock.Any()).DoAndReturn(func(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+		humanPausedHPA.DeepCopyInto(obj.(*autoscalingv2.HorizontalPodAutoscaler))
+		return nil
+	}).Times(1)
+	mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{Namespace: hpaTestNamespace, Name: "deleted-hpa"}, gomock.Any()).
+		Return(apierrors.NewNotFound(schema.GroupResource{Group: "autoscaling", Resource: "horizontalpodautoscalers"}, "deleted-hpa")).Times(1)
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Times(0)
+
+	h := newHPACoordinator(mockClient, logr.Discard(), hpaTestNamespace, &kcmObjectRef)
+	g.Expect(h.resume(context.Background(), nil)).To(Succeed(), "nothing should be read if no HorizontalPodAutoscaler has been paused")
+	g.Expect(h.resume(context.Background(), []string{"manual-hpa", "deleted-hpa"})).To(Succeed())
+}
+
+func TestHPACoordinationWhenListingHPAsFails(t *testing.T) {
+	g := NewWithT(t)
+	table := []struct {
+		description string
+		err         error
+		expectError bool
+	}{
+		{"forbidden error should skip coordination", apierrors.NewForbidden(schema.GroupResource{Group: "autoscaling", Resource: "horizontalpodautoscalers"}, "", errors.New("forbidden")), false},
+		{"other errors should be returned", errors.New("list failed"), true},
+	}
+	for _, entry := range table {
+		t.Run(entry.description, func(t *testing.T) {
+			mockClient := mockclient.NewMockClient(gomock.NewController(t))
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(entry.err).Times(1)
+			_, err := newHPACoordinator(mockClient, logr.Discard(), hpaTestNamespace, &kcmObjectRef).pause(context.Background())
+			if entry.expectError {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestIsHPATargetingResource(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(isHPATargetingResource(createHPA("hpa", "Deployment", kcmObjectRef.Name, "apps/v1", nil), &kcmObjectRef)).To(BeTrue())
+	g.Expect(isHPATargetingResource(createHPA("hpa", "Deployment", kcmObjectRef.Name, "apps/v1beta2", nil), &kcmObjectRef)).To(BeTrue())
+	g.Expect(isHPATargetingResource(createHPA("hpa", "StatefulSet", kcmObjectRef.Name, "apps/v1", nil), &kcmObjectRef)).To(BeFalse())
+	g.Expect(isHPATargetingResource(createHPA("hpa", "Deployment", mcmObjectRef.Name, "apps/v1", nil), &kcmObjectRef)).To(BeFalse())
+	g.Expect(isHPATargetingResource(createHPA("hpa", "Deployment", kcmObjectRef.Name, "extensions/v1beta1", nil), &kcmObjectRef)).To(BeFalse())
+}
+
+func createHPA(name, targetKind, targetName, targetAPIVersion string, behavior *autoscalingv2.HorizontalPodAutoscalerBehavior) autoscalingv2.HorizontalPodAutoscaler {
+	return autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: hpaTestNamespace,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				Kind:       targetKind,
+				Name:       targetName,
+				APIVersion: targetAPIVersion,
+			},
+			MaxReplicas: 3,
+			Behavior:    behavior,
+		},
+	}
+}
@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// ResourceUpdateLimiter limits the number of dependent resources whose replicas are updated concurrently across all shoots of a seed. It is
+// shared by all Scaler instances. A slot is only held while the replicas of a single dependent resource are updated, a scale flow therefore
+// acquires a slot per resource it updates. Resource updates which exceed the limit are queued and resumed in the order in which they have
+// been queued, so that no shoot is starved by others.
+type ResourceUpdateLimiter struct {
+	mu            sync.Mutex
+	maxConcurrent int
+	running       int
+	queue         *list.List
+}
+
+// NewResourceUpdateLimiter creates a ResourceUpdateLimiter which allows at most maxConcurrent resource updates to run concurrently. maxConcurrent must be positive.
+func NewResourceUpdateLimiter(maxConcurrent int) *ResourceUpdateLimiter {
+	return &ResourceUpdateLimiter{
+		maxConcurrent: maxConcurrent,
+		queue:         list.New(),
+	}
+}
+
+// queuedUpdate is a resource update which waits for a free slot. ready is closed once the slot has been handed over to it.
+type queuedUpdate struct {
+	ready chan struct{}
+	op    operation
+}
+
+// acquire blocks until the resource update for the given operation may run, or the context is cancelled. If it returns nil,
+// then release has to be called once the resource update has completed.
+func (l *ResourceUpdateLimiter) acquire(ctx context.Context, op operation) error {
+	l.mu.Lock()
+	if l.running < l.maxConcurrent && l.queue.Len() == 0 {
+		l.running++
+		runningResourceUpdates.Inc()
+		l.mu.Unlock()
+		return nil
+	}
+	qu := &queuedUpdate{ready: make(chan struct{}), op: op}
+	elem := l.queue.PushBack(qu)
+	queuedResourceUpdates.WithLabelValues(op.String()).Inc()
+	l.mu.Unlock()
+
+	queuedAt := time.Now()
+	select {
+	case <-qu.ready:
+		resourceUpdateQueueWaitSeconds.WithLabelValues(op.String()).Observe(time.Since(queuedAt).Seconds())
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		select {
+		case <-qu.ready:
+			// the slot has been handed over concurrently, therefore it is passed on.
+			l.releaseLocked()
+		default:
+			l.queue.Remove(elem)
+			queuedResourceUpdates.WithLabelValues(op.String()).Dec()
+		}
+		return ctx.Err()
+	}
+}
+
+// release frees the slot of a completed resource update and hands it over to the resource update which has been queued first.
+func (l *ResourceUpdateLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.releaseLocked()
+}
+
+func (l *ResourceUpdateLimiter) releaseLocked() {
+	front := l.queue.Front()
+	if front == nil {
+		l.running--
+		runningResourceUpdates.Dec()
+		return
+	}
+	qu := l.queue.Remove(front).(*queuedUpdate)
+	queuedResourceUpdates.WithLabelValues(qu.op.String()).Dec()
+	close(qu.ready)
+}
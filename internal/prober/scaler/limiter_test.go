@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package scaler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	mockscale "github.com/gardener/dependency-watchdog/internal/mock/client-go/scale"
+	mockclient "github.com/gardener/dependency-watchdog/internal/mock/controller-runtime/client"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestResourceUpdateLimiterShouldStartQueuedUpdatesInOrder(t *testing.T) {
+	g := NewWithT(t)
+	limiter := NewResourceUpdateLimiter(1)
+	g.Expect(limiter.acquire(context.Background(), scaleUp)).To(Succeed())
+
+	started := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			if err := limiter.acquire(context.Background(), scaleUp); err == nil {
+				started <- i
+			}
+		}(i)
+		// wait until the update has been queued, so that the order of the queue is deterministic
+		g.Eventually(limiter.queuedCount).Should(Equal(i + 1))
+	}
+	g.Consistently(started, 50*time.Millisecond).ShouldNot(Receive(), "no queued update should start while the limit is reached")
+
+	for i := 0; i < 3; i++ {
+		limiter.release()
+		g.Eventually(started).Should(Receive(Equal(i)))
+	}
+	limiter.release()
+	g.Expect(limiter.running).To(BeZero())
+}
+
+func TestResourceUpdateLimiterShouldRemoveCancelledUpdatesFromQueue(t *testing.T) {
+	g := NewWithT(t)
+	limiter := NewResourceUpdateLimiter(1)
+	g.Expect(limiter.acquire(context.Background(), scaleDown)).To(Succeed())
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- limiter.acquire(ctx, scaleDown)
+	}()
+	g.Eventually(limiter.queuedCount).Should(Equal(1))
+	cancelFn()
+	g.Eventually(errCh).Should(Receive(MatchError(context.Canceled)))
+	g.Expect(limiter.queuedCount()).To(BeZero())
+
+	limiter.release()
+	g.Expect(limiter.running).To(BeZero(), "the slot should be freed as no update is queued")
+	g.Expect(limiter.acquire(context.Background(), scaleUp)).To(Succeed())
+	limiter.release()
+}
+
+func TestResourceUpdateLimiterShouldAllowConcurrentUpdatesUpToLimit(t *testing.T) {
+	g := NewWithT(t)
+	limiter := NewResourceUpdateLimiter(2)
+	g.Expect(limiter.acquire(context.Background(), scaleUp)).To(Succeed())
+	g.Expect(limiter.acquire(context.Background(), scaleDown)).To(Succeed())
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelFn()
+	g.Expect(limiter.acquire(ctx, scaleUp)).To(MatchError(context.DeadlineExceeded))
+	limiter.release()
+	limiter.release()
+	g.Expect(limiter.running).To(BeZero())
+}
+
+func TestScaleFlowShouldNotWaitForFreeSlotIfNoResourceIsUpdated(t *testing.T) {
+	g := NewWithT(t)
+	limiter := NewResourceUpdateLimiter(1)
+	g.Expect(limiter.acquire(context.Background(), scaleDown)).To(Succeed())
+	defer limiter.release()
+
+	ctrl := gomock.NewController(t)
+	cl := mockclient.NewMockClient(ctrl)
+	// scaling of the dependent resource is ignored, therefore the scale flow does not update it.
+	cl.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&metav1.PartialObjectMetadata{})).DoAndReturn(
+		func(_ context.Context, _ client.ObjectKey, obj *metav1.PartialObjectMetadata, _ ...client.GetOption) error {
+			obj.Annotations = map[string]string{IgnoreScalingAnnotationKey: "true"}
+			return nil
+		}).AnyTimes()
+	scalesGetter := mockscale.NewMockScalesGetter(ctrl)
+	scalesGetter.EXPECT().Scales(gomock.Any()).Return(mockscale.NewMockScaleInterface(ctrl)).AnyTimes()
+	ds := NewScaler("shoot--test--limiter", []papi.DependentResourceInfo{createTestDeploymentDependentResourceInfo(kcmObjectRef.Name, 0, 0, nil, nil, false)},
+		cl, scalesGetter, logr.Discard(), WithResourceUpdateLimiter(limiter))
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFn()
+	g.Expect(ds.ScaleDown(ctx, "node lease probe failed")).To(Succeed(), "a scale flow which does not update any resource should not wait for a free slot")
+	g.Expect(limiter.queuedCount()).To(BeZero())
+}
+
+func (l *ResourceUpdateLimiter) queuedCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.queue.Len()
+}
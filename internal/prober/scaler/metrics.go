@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "dwd"
+	metricsSubsystem = "prober"
+	operationLabel   = "operation"
+)
+
+var (
+	runningResourceUpdates = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "running_resource_updates",
+		Help:      "Number of dependent resource updates which hold a slot of the seed-wide resource update limiter.",
+	})
+	queuedResourceUpdates = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "queued_resource_updates",
+		Help:      "Number of dependent resource updates which wait for a free slot of the seed-wide resource update limiter.",
+	}, []string{operationLabel})
+	resourceUpdateQueueWaitSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "resource_update_queue_wait_seconds",
+		Help:      "Duration for which queued dependent resource updates have waited for a free slot of the seed-wide resource update limiter.",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300},
+	}, []string{operationLabel})
+)
+
+func init() {
+	metrics.Registry.MustRegister(runningResourceUpdates, queuedResourceUpdates, resourceUpdateQueueWaitSeconds)
+}
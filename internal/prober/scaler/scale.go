@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/go-logr/logr"
 
@@ -20,11 +21,14 @@ import (
 )
 
 const (
-	// ignoreScalingAnnotationKey is the key for an annotation if present on a resource will suspend any scaling action for that resource.
-	ignoreScalingAnnotationKey = "dependency-watchdog.gardener.cloud/ignore-scaling"
-	// replicasAnnotationKey is the key for an annotation whose value captures the current spec.replicas prior to scale down for that resource.
+	// IgnoreScalingAnnotationKey is the key for an annotation if present on a resource will suspend any scaling action for that resource.
+	IgnoreScalingAnnotationKey = "dependency-watchdog.gardener.cloud/ignore-scaling"
+	// ReplicasAnnotationKey is the key for an annotation whose value captures the current spec.replicas prior to scale down for that resource.
 	// This is used when DWD attempts to restore the state of the resource it scale down.
-	replicasAnnotationKey = "dependency-watchdog.gardener.cloud/replicas"
+	ReplicasAnnotationKey = "dependency-watchdog.gardener.cloud/replicas"
+	// AllowScaleUpAnnotationKey is the key for an annotation which allows to scale up a resource held down by DWD despite the scale protection webhook.
+	// In contrast to IgnoreScalingAnnotationKey it does not suspend scaling by DWD and it is removed by DWD with the next scale-down of the resource.
+	AllowScaleUpAnnotationKey = "dependency-watchdog.gardener.cloud/allow-scale-up"
 	// defaultScaleUpReplicas is the default value of number of replicas for a scale-up operation by a probe when the external probe transitions from failed to success.
 	defaultScaleUpReplicas int32 = 1
 	// defaultScaleDownReplicas is the default value of number of replicas for a scale-down operation by a probe when the external probe transitions from success to failed.
@@ -36,23 +40,25 @@ type resourceScaler interface {
 }
 
 type resScaler struct {
-	client       client.Client
-	scaler       scalev1.ScaleInterface
-	logger       logr.Logger
-	namespace    string
-	resourceInfo scalableResourceInfo
-	opts         *scalerOptions
+	client         client.Client
+	scaler         scalev1.ScaleInterface
+	logger         logr.Logger
+	namespace      string
+	resourceInfo   scalableResourceInfo
+	opts           *scalerOptions
+	hpaCoordinator *hpaCoordinator
 }
 
-func newResourceScaler(client client.Client, scaler scalev1.ScaleInterface, logger logr.Logger, opts *scalerOptions, namespace string, resourceInfo scalableResourceInfo) resourceScaler {
-	resLogger := logger.WithValues("resNamespace", namespace, "kind", resourceInfo.ref.Kind, "apiVersion", resourceInfo.ref.APIVersion, "name", resourceInfo.ref.Name, "level", resourceInfo.level)
+func newResourceScaler(client client.Client, scaler scalev1.ScaleInterface, logger logr.Logger, opts *scalerOptions, resourceInfo scalableResourceInfo) resourceScaler {
+	resLogger := logger.WithValues("resNamespace", resourceInfo.namespace, "kind", resourceInfo.ref.Kind, "apiVersion", resourceInfo.ref.APIVersion, "name", resourceInfo.ref.Name, "level", resourceInfo.level)
 	return &resScaler{
-		client:       client,
-		scaler:       scaler,
-		logger:       resLogger,
-		namespace:    namespace,
-		resourceInfo: resourceInfo,
-		opts:         opts,
+		client:         client,
+		scaler:         scaler,
+		logger:         resLogger,
+		namespace:      resourceInfo.namespace,
+		resourceInfo:   resourceInfo,
+		opts:           opts,
+		hpaCoordinator: newHPACoordinator(client, resLogger, resourceInfo.namespace, resourceInfo.ref),
 	}
 }
 
@@ -72,12 +78,16 @@ func (r *resScaler) scale(ctx context.Context) error {
 			r.logger.Info("Resource not found. Ignoring this resource as its existence is marked as optional")
 			return nil
 		}
+		if apierrors.IsForbidden(err) {
+			r.logger.Error(err, "Not permitted to access resource. Ensure that DWD has been granted the permissions to scale resources in the namespace of the resource")
+			return err
+		}
 		r.logger.Error(err, "Error trying to get annotations for resource")
 		return err
 	}
 
-	if ignoreScaling(resourceAnnot) {
-		r.logger.Info("Scaling ignored due to explicit instruction via annotation", "annotation", ignoreScalingAnnotationKey)
+	if IsScalingIgnored(resourceAnnot) {
+		r.logger.Info("Scaling ignored due to explicit instruction via annotation", "annotation", IgnoreScalingAnnotationKey)
 		return nil
 	}
 
@@ -96,6 +106,13 @@ func (r *resScaler) scale(ctx context.Context) error {
 	} else {
 		if r.resourceInfo.operation == scaleUp {
 			r.logger.Info("Skipping scale-up for resource as current spec replicas > 0")
+			// the resource could have been restored by a previous attempt or by another actor, the scaling history is completed nevertheless.
+			if err := r.resumeHPAs(ctx, resourceAnnot); err != nil {
+				return err
+			}
+			if err := r.recordRestored(ctx, resourceAnnot, scaleSubRes.Spec.Replicas); err != nil {
+				return err
+			}
 		} else {
 			r.logger.Info("Skipping scale-down for resource as current spec replicas == 0")
 		}
@@ -128,17 +145,38 @@ func (r *resScaler) waitTillMinTargetReplicasReached(ctx context.Context) error
 	return nil
 }
 
+// updateResourceAndScale updates the replicas of the resource. If a resource update limiter is configured, then it waits for a free slot before
+// the resource is updated and holds it until all updates are done, waiting for the resource to reach its target replicas is not limited.
 func (r *resScaler) updateResourceAndScale(ctx context.Context, scaleSubRes *autoscalingv1.Scale, annot map[string]string) error {
-	childCtx, cancelFn := context.WithTimeout(ctx, r.resourceInfo.timeout)
+	if limiter := r.opts.resourceUpdateLimiter; limiter != nil {
+		if err := limiter.acquire(ctx, r.resourceInfo.operation); err != nil {
+			return fmt.Errorf("failed to wait for a free slot to %s resource: %w", r.resourceInfo.operation, err)
+		}
+		defer limiter.release()
+	}
+	// Updating the replicas annotation and the scale subresource should either happen together or not at all. A scale flow can be
+	// cancelled at any time when the probe outcome changes, therefore the cancellation is not propagated to this section and it is
+	// only bound by the configured timeout.
+	childCtx, cancelFn := context.WithTimeout(context.WithoutCancel(ctx), r.resourceInfo.timeout)
 	defer cancelFn()
 
 	// update the annotation capturing the current spec.replicas as the annotation value if the operation is scale down.
 	// This allows restoration of the resource to the same replica count when a subsequent scale up operation is triggered.
+	// Along with it the scaling history is recorded which captures when and why the resource has been scaled down.
 	if r.resourceInfo.operation == scaleDown {
-		patchBytes := []byte(fmt.Sprintf("{\"metadata\":{\"annotations\":{\"%s\":\"%s\"}}}", replicasAnnotationKey, strconv.Itoa(int(scaleSubRes.Spec.Replicas))))
-		err := util.PatchResourceAnnotations(ctx, r.client, r.namespace, r.resourceInfo.ref, patchBytes)
+		// HorizontalPodAutoscalers targeting the resource are paused first, so that they do not scale up the resource
+		// once it has been scaled down.
+		pausedHPAs, err := r.hpaCoordinator.pause(childCtx)
+		if err != nil {
+			r.logger.Error(err, "Failed to pause HorizontalPodAutoscalers targeting the resource before scaling it down")
+			return err
+		}
+		patchBytes, err := createScaleDownAnnotationsPatch(scaleSubRes.Spec.Replicas, getScaleReason(ctx), time.Now(), pausedHPAs)
 		if err != nil {
-			r.logger.Error(err, "Failed to update annotation to capture the current replicas before scaling it down")
+			return err
+		}
+		if err = util.PatchResourceAnnotations(childCtx, r.client, r.namespace, r.resourceInfo.ref, patchBytes); err != nil {
+			r.logger.Error(err, "Failed to update annotations to capture the current replicas and scaling history before scaling it down")
 			return err
 		}
 	}
@@ -149,7 +187,7 @@ func (r *resScaler) updateResourceAndScale(ctx context.Context, scaleSubRes *aut
 	}
 
 	// need the updated scale subresource
-	gr, scaleSubRes, err := util.GetScaleResource(ctx, r.client, r.scaler, r.logger, r.resourceInfo.ref, r.resourceInfo.timeout)
+	gr, scaleSubRes, err := util.GetScaleResource(childCtx, r.client, r.scaler, r.logger, r.resourceInfo.ref, r.resourceInfo.timeout)
 	if err != nil {
 		return err
 	}
@@ -163,6 +201,38 @@ func (r *resScaler) updateResourceAndScale(ctx context.Context, scaleSubRes *aut
 	if _, err = r.scaler.Update(childCtx, *gr, scaleSubRes, metav1.UpdateOptions{}); err != nil {
 		return err
 	}
+	if r.resourceInfo.operation == scaleUp {
+		if err = r.resumeHPAs(childCtx, annot); err != nil {
+			return err
+		}
+		return r.recordRestored(childCtx, annot, targetReplicas)
+	}
+	return nil
+}
+
+// resumeHPAs resumes the HorizontalPodAutoscalers which have been paused by DWD as recorded in the active scaling history of the resource.
+// They are resumed before the scaling history is marked as restored, so that resuming them is retried by the next scale-up if it fails.
+func (r *resScaler) resumeHPAs(ctx context.Context, annot map[string]string) error {
+	pausedHPAs, err := getPausedHPAs(annot)
+	if err != nil || len(pausedHPAs) == 0 {
+		return err
+	}
+	return r.hpaCoordinator.resume(ctx, pausedHPAs)
+}
+
+// recordRestored removes the replicas annotation and marks the scaling history of the resource as restored. It is a no-op if the
+// resource carries neither of them.
+func (r *resScaler) recordRestored(ctx context.Context, annot map[string]string, restoredReplicas int32) error {
+	patchBytes, err := createRestoredAnnotationsPatch(annot, restoredReplicas, time.Now(), getScaleReason(ctx))
+	if err != nil || patchBytes == nil {
+		return err
+	}
+	childCtx, cancelFn := context.WithTimeout(context.WithoutCancel(ctx), r.resourceInfo.timeout)
+	defer cancelFn()
+	if err = util.PatchResourceAnnotations(childCtx, r.client, r.namespace, r.resourceInfo.ref, patchBytes); err != nil {
+		r.logger.Error(err, "Failed to update annotations to record the restoration of the resource")
+		return err
+	}
 	return nil
 }
 
@@ -170,19 +240,57 @@ func (r *resScaler) determineTargetReplicas(annotations map[string]string) (int3
 	if r.resourceInfo.operation == scaleDown {
 		return defaultScaleDownReplicas, nil
 	}
-	if replicasStr, ok := annotations[replicasAnnotationKey]; ok {
+	if replicasStr, ok := annotations[ReplicasAnnotationKey]; ok {
 		replicas, err := strconv.Atoi(replicasStr)
 		if err != nil {
-			return 0, fmt.Errorf("unexpected and invalid replicasStr set as value for annotation: %s for resource, Err: %w", replicasAnnotationKey, err)
+			return 0, fmt.Errorf("unexpected and invalid replicasStr set as value for annotation: %s for resource, Err: %w", ReplicasAnnotationKey, err)
 		}
 		return int32(replicas), nil
 	}
-	r.logger.Info("Replicas annotation not found, falling back to default scale-up replicas", "operation", r.resourceInfo.operation, "annotationKey", replicasAnnotationKey, "default-replicas", defaultScaleUpReplicas)
+	r.logger.Info("Replicas annotation not found, falling back to default scale-up replicas", "operation", r.resourceInfo.operation, "annotationKey", ReplicasAnnotationKey, "default-replicas", defaultScaleUpReplicas)
 	return defaultScaleUpReplicas, nil
 }
 
-func ignoreScaling(annotations map[string]string) bool {
-	if val, ok := annotations[ignoreScalingAnnotationKey]; ok {
+// isResourceScaledDown checks if the resource identified by resourceRef has been scaled down to 0 by DWD, i.e. it has 0 replicas
+// and the replicas annotation is set. Resources for which scaling is ignored are never considered as scaled down.
+func isResourceScaledDown(ctx context.Context, cl client.Reader, namespace string, resourceRef *autoscalingv1.CrossVersionObjectReference) (bool, error) {
+	annot, err := util.GetResourceAnnotations(ctx, cl, namespace, resourceRef)
+	if err != nil {
+		return false, err
+	}
+	if !isScaleDownRecorded(annot) {
+		return false, nil
+	}
+	replicas, err := util.GetResourceReplicas(ctx, cl, namespace, resourceRef)
+	if err != nil {
+		return false, err
+	}
+	return IsHeldDown(annot, replicas), nil
+}
+
+// IsHeldDown checks if a resource with the given annotations and spec.replicas is currently held down by DWD, i.e. it has been
+// scaled down to 0 by DWD and has not been restored yet. Resources for which scaling is ignored are never considered as held down.
+func IsHeldDown(annotations map[string]string, replicas int32) bool {
+	return isScaleDownRecorded(annotations) && replicas == 0
+}
+
+func isScaleDownRecorded(annotations map[string]string) bool {
+	_, ok := annotations[ReplicasAnnotationKey]
+	return ok && !IsScalingIgnored(annotations)
+}
+
+// IsScalingIgnored checks if scaling has been suspended for a resource with the given annotations.
+func IsScalingIgnored(annotations map[string]string) bool {
+	return isAnnotationTrue(annotations, IgnoreScalingAnnotationKey)
+}
+
+// IsScaleUpAllowed checks if a resource with the given annotations may be scaled up by others while it is held down by DWD.
+func IsScaleUpAllowed(annotations map[string]string) bool {
+	return isAnnotationTrue(annotations, AllowScaleUpAnnotationKey)
+}
+
+func isAnnotationTrue(annotations map[string]string, key string) bool {
+	if val, ok := annotations[key]; ok {
 		b, err := strconv.ParseBool(val)
 		if err != nil {
 			return false
@@ -7,12 +7,16 @@ package scaler
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/util"
 	"github.com/gardener/gardener/pkg/utils/flow"
 	"github.com/go-logr/logr"
+	multierr "github.com/hashicorp/go-multierror"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	scalev1 "k8s.io/client-go/scale"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -31,43 +35,221 @@ const (
 
 // Scaler is a facade to provide scaling operations for kubernetes scalable resources.
 type Scaler interface {
-	// ScaleUp restores the replicas of a kubernetes resource prior to scale down.
-	ScaleUp(ctx context.Context) error
-	// ScaleDown scales down a kubernetes scalable resource to 0.
-	ScaleDown(ctx context.Context) error
+	// ScaleUp restores the replicas of a kubernetes resource prior to scale down. A non-empty reason is only expected if the resources are
+	// restored although the probe which scaled them down still fails, it is recorded in the scaling history of each restored resource.
+	ScaleUp(ctx context.Context, reason string) error
+	// ScaleDown scales down a kubernetes scalable resource to 0. The reason is recorded in the scaling history of each scaled down resource.
+	ScaleDown(ctx context.Context, reason string) error
+	// IsScaledDown checks if any of the dependent resources has been scaled down to 0 by DWD and has therefore
+	// the replicas annotation set. It is used to determine if a scale up is required when there is no record of a previous scale operation.
+	IsScaledDown(ctx context.Context) (bool, error)
+	// RecordScaleUpGate records the state of a gate which holds back the scale-up in the scaling history of each dependent resource
+	// which is still scaled down by DWD. An empty scaleUpGate clears a previously recorded state.
+	RecordScaleUpGate(ctx context.Context, scaleUpGate string) error
 }
 
 // NewScaler creates an instance of Scaler.
-func NewScaler(namespace string, dependentResourceInfos []papi.DependentResourceInfo, client client.Client, scalerGetter scalev1.ScalesGetter, logger logr.Logger, options ...scalerOption) Scaler {
+func NewScaler(namespace string, dependentResourceInfos []papi.DependentResourceInfo, client client.Client, scalerGetter scalev1.ScalesGetter, logger logr.Logger, options ...ScalerOption) Scaler {
 	opts := buildScalerOptions(options...)
 
-	fc := newFlowCreator(client, scalerGetter.Scales(namespace), logger, opts, dependentResourceInfos)
-	scaleUpFlow := fc.createFlow(fmt.Sprintf("scale-up-%s", namespace), namespace, scaleUp)
-	logger.V(1).Info("Created scaleUpFlow", "flowStepInfos", scaleUpFlow.flowStepInfos)
-	scaleDownFlow := fc.createFlow(fmt.Sprintf("scale-down-%s", namespace), namespace, scaleDown)
-	logger.V(1).Info("Created scaleDownFlow", "flowStepInfos", scaleDownFlow.flowStepInfos)
-
-	return &scaleFlowRunner{
-		namespace:     namespace,
-		options:       opts,
-		scaleUpFlow:   scaleUpFlow.flow,
-		scaleDownFlow: scaleDownFlow.flow,
+	ds := &scaleFlowRunner{
+		namespace:              namespace,
+		client:                 client,
+		scalesGetter:           scalerGetter,
+		logger:                 logger,
+		dependentResourceInfos: resolveNamespaces(logger, namespace, dependentResourceInfos),
+		options:                opts,
+		hookRunner:             newScaleHookRunner(opts.scaleHooks, logger),
+	}
+	// resources identified via resource selectors can change at any time, therefore the flows are only created upfront
+	// if all dependent resources are identified via a Ref. Otherwise, they are created each time a flow is run.
+	if !hasResourceSelectors(ds.dependentResourceInfos) {
+		ds.scaleUpFlow = ds.createFlow(ds.dependentResourceInfos, scaleUp)
+		ds.scaleDownFlow = ds.createFlow(ds.dependentResourceInfos, scaleDown)
 	}
+	return ds
 }
 
 type scaleFlowRunner struct {
-	namespace     string
-	scaleDownFlow *flow.Flow
-	scaleUpFlow   *flow.Flow
-	options       *scalerOptions
+	namespace              string
+	client                 client.Client
+	scalesGetter           scalev1.ScalesGetter
+	logger                 logr.Logger
+	dependentResourceInfos []papi.DependentResourceInfo
+	scaleDownFlow          *flow.Flow
+	scaleUpFlow            *flow.Flow
+	options                *scalerOptions
+	hookRunner             *scaleHookRunner
+	// lastOpMu guards lastOp.
+	lastOpMu sync.Mutex
+	// lastOp is the operation of the last flow which has been run. It is nil if no flow has been run yet.
+	lastOp *operation
+}
+
+func (ds *scaleFlowRunner) ScaleDown(ctx context.Context, reason string) error {
+	return ds.runFlow(ctx, scaleDown, reason)
+}
+
+func (ds *scaleFlowRunner) ScaleUp(ctx context.Context, reason string) error {
+	return ds.runFlow(ctx, scaleUp, reason)
+}
+
+// runFlow runs the flow for the given operation. The flow is enclosed by the calls to the scale hooks if the operation differs from the
+// one of the previous flow, or if the replicas of any dependent resource have to be changed. A flow which is repeated on every probe
+// while the outcome of the probe does not change therefore only calls the scale hooks once. The post-scale hooks are also called
+// if the flow has been cancelled, so that they are not left waiting for the outcome of the scale operation.
+func (ds *scaleFlowRunner) runFlow(ctx context.Context, opType operation, reason string) error {
+	resInfos, err := ds.getDependentResourceInfos(ctx)
+	if err != nil {
+		return err
+	}
+	if !ds.shouldRunHooks(ctx, resInfos, opType) {
+		return ds.getFlow(resInfos, opType).Run(withScaleReason(ctx, reason), flow.Opts{})
+	}
+	payload := newScaleHookPayload(ds.namespace, opType, reason, resInfos)
+	if err = ds.hookRunner.run(ctx, preScaleHookPhase, payload); err != nil {
+		return fmt.Errorf("pre-scale hooks failed, skipping %s: %w", opType, err)
+	}
+	ds.setLastOp(opType)
+	flowErr := ds.getFlow(resInfos, opType).Run(withScaleReason(ctx, reason), flow.Opts{})
+	if flowErr != nil {
+		payload.Error = flowErr.Error()
+	}
+	if err = ds.hookRunner.run(context.WithoutCancel(ctx), postScaleHookPhase, payload); err != nil {
+		return multierr.Append(flowErr, fmt.Errorf("post-scale hooks failed after %s: %w", opType, err)).ErrorOrNil()
+	}
+	return flowErr
+}
+
+// shouldRunHooks checks if the scale hooks have to be called for a flow of the given operation. This is the case if scale hooks are
+// configured and either the operation differs from the one of the previous flow, or the replicas of any dependent resource have to be
+// changed. If the replicas of a dependent resource cannot be read, then the hooks are called.
+func (ds *scaleFlowRunner) shouldRunHooks(ctx context.Context, resInfos []papi.DependentResourceInfo, opType operation) bool {
+	if len(ds.hookRunner.hooks) == 0 {
+		return false
+	}
+	ds.lastOpMu.Lock()
+	lastOp := ds.lastOp
+	ds.lastOpMu.Unlock()
+	if lastOp == nil || *lastOp != opType {
+		return true
+	}
+	for _, resInfo := range resInfos {
+		changesReplicas, err := ds.changesReplicas(ctx, resInfo, opType)
+		if err != nil {
+			ds.logger.V(4).Info("Failed to check if the replicas of a dependent resource have to be changed, scale hooks are called", "ref", resInfo.Ref, "err", err.Error())
+			return true
+		}
+		if changesReplicas {
+			return true
+		}
+	}
+	ds.logger.V(4).Info("Skipping scale hooks as no dependent resource has to be scaled", "operation", opType)
+	return false
 }
 
-func (ds *scaleFlowRunner) ScaleDown(ctx context.Context) error {
-	return ds.scaleDownFlow.Run(ctx, flow.Opts{})
+// changesReplicas checks if the given operation has to change the replicas of the dependent resource. Resources which are not found
+// or for which scaling is ignored are never changed.
+func (ds *scaleFlowRunner) changesReplicas(ctx context.Context, resInfo papi.DependentResourceInfo, opType operation) (bool, error) {
+	annot, err := util.GetResourceAnnotations(ctx, ds.client, resInfo.Namespace, resInfo.Ref)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if IsScalingIgnored(annot) {
+		return false, nil
+	}
+	replicas, err := util.GetResourceReplicas(ctx, ds.client, resInfo.Namespace, resInfo.Ref)
+	if err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return opType.shouldScaleReplicas(replicas), nil
+}
+
+func (ds *scaleFlowRunner) setLastOp(opType operation) {
+	ds.lastOpMu.Lock()
+	defer ds.lastOpMu.Unlock()
+	ds.lastOp = &opType
+}
+
+func (ds *scaleFlowRunner) IsScaledDown(ctx context.Context) (bool, error) {
+	resInfos, err := ds.getDependentResourceInfos(ctx)
+	if err != nil {
+		return false, err
+	}
+	var reader client.Reader = ds.client
+	if ds.options.cachedReader != nil {
+		reader = ds.options.cachedReader
+	}
+	for _, resInfo := range resInfos {
+		scaledDown, err := isResourceScaledDown(ctx, reader, resInfo.Namespace, resInfo.Ref)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		if scaledDown {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (ds *scaleFlowRunner) RecordScaleUpGate(ctx context.Context, scaleUpGate string) error {
+	resInfos, err := ds.getDependentResourceInfos(ctx)
+	if err != nil {
+		return err
+	}
+	for _, resInfo := range resInfos {
+		annot, err := util.GetResourceAnnotations(ctx, ds.client, resInfo.Namespace, resInfo.Ref)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		patchBytes, err := createScaleUpGateAnnotationsPatch(annot, scaleUpGate)
+		if err != nil {
+			return err
+		}
+		if patchBytes == nil {
+			continue
+		}
+		if err = util.PatchResourceAnnotations(ctx, ds.client, resInfo.Namespace, resInfo.Ref, patchBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getFlow returns the flow for the given operation. If the flow has not been created upfront, then a new flow is created
+// for the given dependent resource infos whose resource selectors have been resolved.
+func (ds *scaleFlowRunner) getFlow(resInfos []papi.DependentResourceInfo, opType operation) *flow.Flow {
+	if opType == scaleUp && ds.scaleUpFlow != nil {
+		return ds.scaleUpFlow
+	}
+	if opType == scaleDown && ds.scaleDownFlow != nil {
+		return ds.scaleDownFlow
+	}
+	return ds.createFlow(resInfos, opType)
+}
+
+// getDependentResourceInfos returns the dependent resource infos where all resource selectors have been resolved.
+func (ds *scaleFlowRunner) getDependentResourceInfos(ctx context.Context) ([]papi.DependentResourceInfo, error) {
+	if !hasResourceSelectors(ds.dependentResourceInfos) {
+		return ds.dependentResourceInfos, nil
+	}
+	return resolveDependentResourceInfos(ctx, ds.client, ds.logger, ds.dependentResourceInfos)
 }
 
-func (ds *scaleFlowRunner) ScaleUp(ctx context.Context) error {
-	return ds.scaleUpFlow.Run(ctx, flow.Opts{})
+func (ds *scaleFlowRunner) createFlow(dependentResourceInfos []papi.DependentResourceInfo, opType operation) *flow.Flow {
+	fc := newFlowCreator(ds.client, ds.scalesGetter, ds.logger, ds.options, dependentResourceInfos)
+	sf := fc.createFlow(fmt.Sprintf("%s-%s", opType, ds.namespace), opType)
+	ds.logger.V(1).Info("Created scale flow", "operation", opType, "flowStepInfos", sf.flowStepInfos)
+	return sf.flow
 }
 
 // getMinTargetReplicas gets the minimum target replicas based on the operation.
@@ -107,6 +289,7 @@ func (i operation) minTargetReplicasReached(currentReplicas int32) bool {
 // scalableResourceInfo captures scaling configuration for a DependentResourceInfo.
 type scalableResourceInfo struct {
 	ref          *autoscalingv1.CrossVersionObjectReference
+	namespace    string
 	optional     bool
 	level        int
 	initialDelay time.Duration
@@ -115,6 +298,6 @@ type scalableResourceInfo struct {
 }
 
 func (r scalableResourceInfo) String() string {
-	return fmt.Sprintf("{Resource ref: %#v, level: %d, initialDelay: %#v, timeout: %#v, operation: %v}",
-		*r.ref, r.level, r.initialDelay, r.timeout, r.operation)
+	return fmt.Sprintf("{Resource ref: %#v, namespace: %s, level: %d, initialDelay: %#v, timeout: %#v, operation: %v}",
+		*r.ref, r.namespace, r.level, r.initialDelay, r.timeout, r.operation)
 }
@@ -38,6 +38,7 @@ const (
 	defaultTestResourceCheckInterval                 = 1 * time.Second
 	defaultTestScaleResourceBackoff                  = 100 * time.Millisecond
 	expectedSpecReplicasAfterSuccessfulScaleDownTest = 0
+	testScaleDownReason                              = "node lease probe failed"
 )
 
 func TestScalerSuite(t *testing.T) {
@@ -57,6 +58,8 @@ func TestScalerSuite(t *testing.T) {
 		{"test scale down then scale up when ignore scaling annotation is present", testScaleDownThenScaleUpWhenIgnoreScalingAnnotationIsPresent},
 		{"test scale up should not happen if current replica count is positive", testResourceShouldNotScaleUpIfCurrentReplicaCountIsPositive},
 		{"test scale up when replica annotation has invalid value", testScaleUpShouldReturnErrorWhenReplicasAnnotationsHasInvalidValue},
+		{"test is scaled down only after a scale down", testIsScaledDownOnlyAfterScaleDown},
+		{"test scaling history is recorded on scale down and completed on scale up", testScalingHistoryIsRecordedAndCompleted},
 	}
 	for _, test := range tests {
 		test := test
@@ -92,13 +95,13 @@ func testScaleDownThenScaleUpWhenIgnoreScalingAnnotationIsNotPresent(t *testing.
 		createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, entry.caReplicas, nil)
 		createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, entry.kcmReplicas, nil)
 
-		err := ds.ScaleDown(context.Background())
+		err := ds.ScaleDown(context.Background(), testScaleDownReason)
 		g.Expect(err).ToNot(HaveOccurred())
 		checkScaleSuccess(g, scaleDown, namespace, caObjectRef.Name, expectedSpecReplicasAfterSuccessfulScaleDownTest)
 		checkScaleSuccess(g, scaleDown, namespace, mcmObjectRef.Name, expectedSpecReplicasAfterSuccessfulScaleDownTest)
 		checkScaleSuccess(g, scaleDown, namespace, kcmObjectRef.Name, expectedSpecReplicasAfterSuccessfulScaleDownTest)
 
-		err = ds.ScaleUp(context.Background())
+		err = ds.ScaleUp(context.Background(), "")
 		g.Expect(err).ToNot(HaveOccurred())
 		checkScaleSuccess(g, scaleUp, namespace, mcmObjectRef.Name, entry.expectedScaledUpMCMReplicas)
 		checkScaleSuccess(g, scaleUp, namespace, caObjectRef.Name, entry.expectedScaledUpCAReplicas)
@@ -138,7 +141,7 @@ func testScaleDownThenScaleUpWhenIgnoreScalingAnnotationIsPresent(t *testing.T)
 		createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, entry.caReplicas, nil)
 		createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, entry.kcmReplicas, entry.annotationsOnKCM)
 
-		err := ds.ScaleDown(context.Background())
+		err := ds.ScaleDown(context.Background(), testScaleDownReason)
 		g.Expect(err).ToNot(HaveOccurred())
 		checkScaleSuccess(g, scaleDown, namespace, caObjectRef.Name, expectedSpecReplicasAfterSuccessfulScaleDownTest)
 		checkScaleSuccess(g, scaleDown, namespace, mcmObjectRef.Name, expectedSpecReplicasAfterSuccessfulScaleDownTest)
@@ -148,7 +151,7 @@ func testScaleDownThenScaleUpWhenIgnoreScalingAnnotationIsPresent(t *testing.T)
 			checkScaleSuccess(g, scaleDown, namespace, kcmObjectRef.Name, expectedSpecReplicasAfterSuccessfulScaleDownTest)
 		}
 
-		err = ds.ScaleUp(context.Background())
+		err = ds.ScaleUp(context.Background(), "")
 		g.Expect(err).ToNot(HaveOccurred())
 		checkScaleSuccess(g, scaleUp, namespace, mcmObjectRef.Name, entry.expectedScaledUpMCMReplicas)
 		checkScaleSuccess(g, scaleUp, namespace, caObjectRef.Name, entry.expectedScaledUpCAReplicas)
@@ -178,8 +181,8 @@ func testScalingWhenMandatoryResourceNotFound(t *testing.T) {
 		expectedUnscaledResourceSpecReplicas int32
 		expectedScaledResourceSpecReplicas   int32
 	}{
-		{0, 0, ds.ScaleUp, scaleUp, mcmObjectRef.Name, caObjectRef.Name, 0, 1},
-		{2, 2, ds.ScaleDown, scaleDown, caObjectRef.Name, mcmObjectRef.Name, 2, expectedSpecReplicasAfterSuccessfulScaleDownTest},
+		{0, 0, scaleUpFn(ds), scaleUp, mcmObjectRef.Name, caObjectRef.Name, 0, 1},
+		{2, 2, scaleDownFn(ds), scaleDown, caObjectRef.Name, mcmObjectRef.Name, 2, expectedSpecReplicasAfterSuccessfulScaleDownTest},
 	}
 	for _, entry := range table {
 		createDeployment(g, namespace, mcmObjectRef.Name, deploymentImageName, entry.mcmReplicas, nil)
@@ -209,8 +212,8 @@ func testScalingWhenOptionalResourceNotFound(t *testing.T) {
 		scalingFn                 func(context.Context) error
 		op                        operation
 	}{
-		{0, 0, 1, 1, ds.ScaleUp, scaleUp},
-		{2, 2, expectedSpecReplicasAfterSuccessfulScaleDownTest, expectedSpecReplicasAfterSuccessfulScaleDownTest, ds.ScaleDown, scaleDown},
+		{0, 0, 1, 1, scaleUpFn(ds), scaleUp},
+		{2, 2, expectedSpecReplicasAfterSuccessfulScaleDownTest, expectedSpecReplicasAfterSuccessfulScaleDownTest, scaleDownFn(ds), scaleDown},
 	}
 	for _, entry := range table {
 		createDeployment(g, namespace, mcmObjectRef.Name, deploymentImageName, entry.mcmReplicas, nil)
@@ -243,8 +246,8 @@ func testGettingScaleSubResourceTimesOut(t *testing.T) {
 		scalingFn                 func(context.Context) error
 		errorString               string
 	}{
-		{0, 0, 0, 0, 0, 0, ds.ScaleUp, "context deadline exceeded"},
-		{1, 1, 1, 1, 1, 1, ds.ScaleDown, "context deadline exceeded"},
+		{0, 0, 0, 0, 0, 0, scaleUpFn(ds), "context deadline exceeded"},
+		{1, 1, 1, 1, 1, 1, scaleDownFn(ds), "context deadline exceeded"},
 	}
 
 	for _, entry := range table {
@@ -283,8 +286,8 @@ func testScalingWhenKindOfResourceIsInvalid(t *testing.T) {
 		expectedScaledResourceSpecReplicas   int32
 		expectedUnscaledResourceSpecReplicas []int32
 	}{
-		{0, 0, 0, ds.ScaleUp, scaleUp, "no matches for kind \"Depoyment\" in version \"apps/v1\"", caObjectRef.Name, []string{mcmObjectRef.Name, kcmObjectRef.Name}, 1, []int32{0, 0}},
-		{2, 2, 2, ds.ScaleDown, scaleDown, "no matches for kind \"Depoyment\" in version \"apps/v1\"", mcmObjectRef.Name, []string{caObjectRef.Name, kcmObjectRef.Name}, expectedSpecReplicasAfterSuccessfulScaleDownTest, []int32{2, 2}},
+		{0, 0, 0, scaleUpFn(ds), scaleUp, "no matches for kind \"Depoyment\" in version \"apps/v1\"", caObjectRef.Name, []string{mcmObjectRef.Name, kcmObjectRef.Name}, 1, []int32{0, 0}},
+		{2, 2, 2, scaleDownFn(ds), scaleDown, "no matches for kind \"Depoyment\" in version \"apps/v1\"", mcmObjectRef.Name, []string{caObjectRef.Name, kcmObjectRef.Name}, expectedSpecReplicasAfterSuccessfulScaleDownTest, []int32{2, 2}},
 	}
 
 	for _, entry := range table {
@@ -323,8 +326,8 @@ func testScalingWhenKindOfResourceIsInvalid(t *testing.T) {
 //		op                        operation
 //		errorString               string
 //	}{
-//		{0, 0, 0, 0, 0, 1, ds.ScaleUp, scaleUp, fmt.Sprintf("timed out waiting for {namespace: %s, resource: %s} to reach minTargetReplicas", namespace, caObjectRef.Name)},
-//		{2, 2, 2, expectedSpecReplicasAfterSuccessfulScaleDownTest, expectedSpecReplicasAfterSuccessfulScaleDownTest, 2, ds.ScaleDown, scaleDown, "timed out waiting"}, // mcm or kcm can return error hence short string is used
+//		{0, 0, 0, 0, 0, 1, scaleUpFn(ds), scaleUp, fmt.Sprintf("timed out waiting for {namespace: %s, resource: %s} to reach minTargetReplicas", namespace, caObjectRef.Name)},
+//		{2, 2, 2, expectedSpecReplicasAfterSuccessfulScaleDownTest, expectedSpecReplicasAfterSuccessfulScaleDownTest, 2, scaleDownFn(ds), scaleDown, "timed out waiting"}, // mcm or kcm can return error hence short string is used
 //	}
 //
 //	for _, entry := range table {
@@ -351,9 +354,9 @@ func testResourceShouldNotScaleUpIfCurrentReplicaCountIsPositive(t *testing.T) {
 	ds := createDefaultScaler(g, probeCfg.DependentResourceInfos)
 	createDeployment(g, namespace, mcmObjectRef.Name, deploymentImageName, 0, nil)
 	createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, 0, nil)
-	createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, 1, map[string]string{replicasAnnotationKey: "2"})
+	createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, 1, map[string]string{ReplicasAnnotationKey: "2"})
 
-	err := ds.ScaleUp(context.Background())
+	err := ds.ScaleUp(context.Background(), "")
 	g.Expect(err).ToNot(HaveOccurred())
 	checkScaleSuccess(g, scaleUp, namespace, caObjectRef.Name, 1)
 	checkScaleSuccess(g, scaleUp, namespace, kcmObjectRef.Name, 1)
@@ -370,9 +373,9 @@ func testScaleUpShouldReturnErrorWhenReplicasAnnotationsHasInvalidValue(t *testi
 	ds := createDefaultScaler(g, probeCfg.DependentResourceInfos)
 	createDeployment(g, namespace, mcmObjectRef.Name, deploymentImageName, 0, nil)
 	createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, 0, nil)
-	createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, 0, map[string]string{replicasAnnotationKey: "foo"})
+	createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, 0, map[string]string{ReplicasAnnotationKey: "foo"})
 
-	err := ds.ScaleUp(context.Background())
+	err := ds.ScaleUp(context.Background(), "")
 	g.Expect(err).ToNot(BeNil())
 	checkScaleSuccess(g, scaleUp, namespace, caObjectRef.Name, 1)
 	matchSpecReplicas(g, namespace, kcmObjectRef.Name, 0)
@@ -386,6 +389,64 @@ func testScaleUpShouldReturnErrorWhenReplicasAnnotationsHasInvalidValue(t *testi
 // utility methods to be used by tests
 // ------------------------------------------------------------------------------------------------------------------
 
+func testIsScaledDownOnlyAfterScaleDown(t *testing.T) {
+	g := NewWithT(t)
+	probeCfg := createProbeConfig(nil)
+	ds := createDefaultScaler(g, probeCfg.DependentResourceInfos)
+	createDeployment(g, namespace, mcmObjectRef.Name, deploymentImageName, 2, nil)
+	createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, 0, nil)
+	createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, 1, nil)
+
+	scaledDown, err := ds.IsScaledDown(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(scaledDown).To(BeFalse(), "resources at 0 replicas without the replicas annotation should not be considered as scaled down")
+
+	g.Expect(ds.ScaleDown(context.Background(), testScaleDownReason)).To(Succeed())
+	scaledDown, err = ds.IsScaledDown(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(scaledDown).To(BeTrue())
+
+	g.Expect(ds.ScaleUp(context.Background(), "")).To(Succeed())
+	scaledDown, err = ds.IsScaledDown(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(scaledDown).To(BeFalse())
+	t.Log("is scaled down test finished")
+}
+
+func testScalingHistoryIsRecordedAndCompleted(t *testing.T) {
+	g := NewWithT(t)
+	probeCfg := createProbeConfig(nil)
+	ds := createDefaultScaler(g, probeCfg.DependentResourceInfos)
+	createDeployment(g, namespace, mcmObjectRef.Name, deploymentImageName, 2, nil)
+	createDeployment(g, namespace, caObjectRef.Name, deploymentImageName, 1, nil)
+	createDeployment(g, namespace, kcmObjectRef.Name, deploymentImageName, 1, nil)
+
+	g.Expect(ds.ScaleDown(context.Background(), testScaleDownReason)).To(Succeed())
+	deploy, err := kindTestEnv.GetDeployment(namespace, mcmObjectRef.Name)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(deploy.Annotations).To(HaveKeyWithValue(ReplicasAnnotationKey, "2"))
+	history, err := getScalingHistory(deploy.Annotations)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(history).ToNot(BeNil())
+	g.Expect(history.State).To(Equal(scalingStateScaledDown))
+	g.Expect(history.OriginalReplicas).To(Equal(int32(2)))
+	g.Expect(history.Reason).To(Equal(testScaleDownReason))
+	g.Expect(history.ScaledDownAt.IsZero()).To(BeFalse())
+	g.Expect(history.RestoredAt).To(BeNil())
+
+	g.Expect(ds.ScaleUp(context.Background(), "")).To(Succeed())
+	deploy, err = kindTestEnv.GetDeployment(namespace, mcmObjectRef.Name)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(deploy.Annotations).ToNot(HaveKey(ReplicasAnnotationKey))
+	history, err = getScalingHistory(deploy.Annotations)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(history).ToNot(BeNil())
+	g.Expect(history.State).To(Equal(scalingStateRestored))
+	g.Expect(history.RestoredAt).ToNot(BeNil())
+	g.Expect(*history.RestoredReplicas).To(Equal(int32(2)))
+	t.Log("scaling history test finished")
+}
+
 func setUpScalerTests(g *WithT) func(g *WithT) {
 	var err error
 	kindTestEnv, err = kind.CreateKindCluster(kind.KindConfig{Name: "scaler-test"})
@@ -402,7 +463,7 @@ func createDefaultScaler(g *WithT, dependentResourceInfos []papi.DependentResour
 
 func createScaler(g *WithT, dependentResourceInfos []papi.DependentResourceInfo, resCheckTimeout time.Duration, resCheckInterval time.Duration, scaleResBackoff time.Duration) Scaler {
 	cfg := kindTestEnv.GetRestConfig()
-	scalesGetter, err := util.CreateScalesGetter(cfg)
+	scalesGetter, _, err := util.CreateScalesGetter(cfg)
 	g.Expect(err).ToNot(HaveOccurred())
 	ds := NewScaler(namespace, dependentResourceInfos, kindTestEnv.GetClient(), scalesGetter, scalerTestLogger,
 		withResourceCheckTimeout(resCheckTimeout), withResourceCheckInterval(resCheckInterval), withScaleResourceBackOff(scaleResBackoff))
@@ -445,6 +506,19 @@ func matchSpecReplicas(g *WithT, namespace string, name string, expectedReplicas
 	return deploy
 }
 
+// scaleDownFn adapts Scaler.ScaleDown to the signature of Scaler.ScaleUp so that both can be used in test tables.
+func scaleUpFn(ds Scaler) func(context.Context) error {
+	return func(ctx context.Context) error {
+		return ds.ScaleUp(ctx, "")
+	}
+}
+
+func scaleDownFn(ds Scaler) func(context.Context) error {
+	return func(ctx context.Context) error {
+		return ds.ScaleDown(ctx, testScaleDownReason)
+	}
+}
+
 func createProbeConfig(timeout *time.Duration) *papi.Config {
 	dependentResourceInfos := createDepResourceInfoArray(timeout)
 	return &papi.Config{DependentResourceInfos: dependentResourceInfos}
@@ -7,7 +7,9 @@ package scaler
 import (
 	"time"
 
+	papi "github.com/gardener/dependency-watchdog/api/prober"
 	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -16,15 +18,19 @@ const (
 	defaultScaleResourceBackoff  = 100 * time.Millisecond
 )
 
-type scalerOption func(options *scalerOptions)
+// ScalerOption configures an optional setting of a Scaler.
+type ScalerOption func(options *scalerOptions)
 
 type scalerOptions struct {
 	resourceCheckTimeout  *time.Duration
 	resourceCheckInterval *time.Duration
 	scaleResourceBackOff  *time.Duration
+	scaleHooks            []papi.ScaleHook
+	resourceUpdateLimiter *ResourceUpdateLimiter
+	cachedReader          client.Reader
 }
 
-func buildScalerOptions(options ...scalerOption) *scalerOptions {
+func buildScalerOptions(options ...ScalerOption) *scalerOptions {
 	opts := new(scalerOptions)
 	for _, opt := range options {
 		opt(opts)
@@ -33,24 +39,47 @@ func buildScalerOptions(options ...scalerOption) *scalerOptions {
 	return opts
 }
 
-func withResourceCheckTimeout(timeout time.Duration) scalerOption {
+func withResourceCheckTimeout(timeout time.Duration) ScalerOption {
 	return func(options *scalerOptions) {
 		options.resourceCheckTimeout = &timeout
 	}
 }
 
-func withResourceCheckInterval(interval time.Duration) scalerOption {
+func withResourceCheckInterval(interval time.Duration) ScalerOption {
 	return func(options *scalerOptions) {
 		options.resourceCheckInterval = &interval
 	}
 }
 
-func withScaleResourceBackOff(interval time.Duration) scalerOption {
+func withScaleResourceBackOff(interval time.Duration) ScalerOption {
 	return func(options *scalerOptions) {
 		options.scaleResourceBackOff = &interval
 	}
 }
 
+// WithScaleHooks configures the scale hooks which are called before and after the dependent resources are scaled.
+func WithScaleHooks(hooks []papi.ScaleHook) ScalerOption {
+	return func(options *scalerOptions) {
+		options.scaleHooks = hooks
+	}
+}
+
+// WithResourceUpdateLimiter configures the limiter which has to be passed before the replicas of a dependent resource are updated. It is shared by all Scaler instances.
+func WithResourceUpdateLimiter(limiter *ResourceUpdateLimiter) ScalerOption {
+	return func(options *scalerOptions) {
+		options.resourceUpdateLimiter = limiter
+	}
+}
+
+// WithCachedReader configures the reader which is used to check if any of the dependent resources is scaled down. This check is done
+// on every probe which has no record of a previous scale operation, therefore it should be served from a cache. The scale flows
+// always use the live client of the Scaler.
+func WithCachedReader(reader client.Reader) ScalerOption {
+	return func(options *scalerOptions) {
+		options.cachedReader = reader
+	}
+}
+
 func fillDefaultsOptions(options *scalerOptions) {
 	if options.resourceCheckTimeout == nil {
 		options.resourceCheckTimeout = pointer.Duration(defaultResourceCheckTimeout)
@@ -55,3 +55,10 @@ func TestBuildScalerOptionsShouldFillDefaultValues(t *testing.T) {
 	g.Expect(*opts.resourceCheckInterval).To(Equal(defaultResourceCheckInterval))
 	g.Expect(*opts.resourceCheckTimeout).To(Equal(defaultResourceCheckTimeout))
 }
+
+func TestWithUpdateLimiter(t *testing.T) {
+	g := NewWithT(t)
+	limiter := NewResourceUpdateLimiter(3)
+	opts := buildScalerOptions(WithResourceUpdateLimiter(limiter))
+	g.Expect(opts.resourceUpdateLimiter).To(BeIdenticalTo(limiter))
+}
@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/util"
+	"github.com/go-logr/logr"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// scaleUpLevelAnnotationKey is the key for an annotation on a resource identified via a resource selector. Its value is the level
+	// at which the resource is scaled up. If it is not set then the level configured for the selector is used.
+	scaleUpLevelAnnotationKey = "dependency-watchdog.gardener.cloud/scale-up-level"
+	// scaleDownLevelAnnotationKey is the key for an annotation on a resource identified via a resource selector. Its value is the level
+	// at which the resource is scaled down. If it is not set then the level configured for the selector is used.
+	scaleDownLevelAnnotationKey = "dependency-watchdog.gardener.cloud/scale-down-level"
+)
+
+// hasResourceSelectors checks if any of the dependentResourceInfos identifies resources via a resource selector.
+func hasResourceSelectors(dependentResourceInfos []papi.DependentResourceInfo) bool {
+	for _, resInfo := range dependentResourceInfos {
+		if resInfo.Selector != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveDependentResourceInfos replaces every papi.DependentResourceInfo that has a resource selector with one papi.DependentResourceInfo
+// per resource in its namespace which matches the selector. A resource which is already identified via a Ref is not added again,
+// the explicit configuration always takes precedence.
+func resolveDependentResourceInfos(ctx context.Context, cl client.Client, logger logr.Logger, dependentResourceInfos []papi.DependentResourceInfo) ([]papi.DependentResourceInfo, error) {
+	resolvedResInfos := make([]papi.DependentResourceInfo, 0, len(dependentResourceInfos))
+	refKeys := sets.New[string]()
+	for _, resInfo := range dependentResourceInfos {
+		if resInfo.Ref != nil {
+			resolvedResInfos = append(resolvedResInfos, resInfo)
+			refKeys.Insert(createRefKey(resInfo.Namespace, resInfo.Ref))
+		}
+	}
+	for _, resInfo := range dependentResourceInfos {
+		if resInfo.Selector == nil {
+			continue
+		}
+		objMetas, err := util.ListResourcesMetadata(ctx, cl, resInfo.Namespace, resInfo.Selector.APIVersion, resInfo.Selector.Kind, resInfo.Selector.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resources of kind %s in namespace %s matching the resource selector: %w", resInfo.Selector.Kind, resInfo.Namespace, err)
+		}
+		for _, objMeta := range objMetas {
+			ref := &autoscalingv1.CrossVersionObjectReference{
+				Kind:       resInfo.Selector.Kind,
+				Name:       objMeta.Name,
+				APIVersion: resInfo.Selector.APIVersion,
+			}
+			refKey := createRefKey(resInfo.Namespace, ref)
+			if refKeys.Has(refKey) {
+				continue
+			}
+			refKeys.Insert(refKey)
+			resolvedResInfos = append(resolvedResInfos, createSelectedDependentResourceInfo(logger, resInfo, ref, objMeta.Annotations))
+		}
+	}
+	return resolvedResInfos, nil
+}
+
+// createSelectedDependentResourceInfo creates a papi.DependentResourceInfo for a resource identified via the resource selector of selectorResInfo.
+// The scale up and scale down levels are taken from the annotations of the resource if present and valid.
+func createSelectedDependentResourceInfo(logger logr.Logger, selectorResInfo papi.DependentResourceInfo, ref *autoscalingv1.CrossVersionObjectReference, annotations map[string]string) papi.DependentResourceInfo {
+	scaleUpInfo := *selectorResInfo.ScaleUpInfo
+	scaleDownInfo := *selectorResInfo.ScaleDownInfo
+	scaleUpInfo.Level = getLevelFromAnnotations(logger, ref, annotations, scaleUpLevelAnnotationKey, scaleUpInfo.Level)
+	scaleDownInfo.Level = getLevelFromAnnotations(logger, ref, annotations, scaleDownLevelAnnotationKey, scaleDownInfo.Level)
+	return papi.DependentResourceInfo{
+		Ref:       ref,
+		Namespace: selectorResInfo.Namespace,
+		// the resource could have been deleted after it has been selected, it should therefore not fail the scale flow.
+		Optional:      true,
+		ScaleUpInfo:   &scaleUpInfo,
+		ScaleDownInfo: &scaleDownInfo,
+	}
+}
+
+func getLevelFromAnnotations(logger logr.Logger, ref *autoscalingv1.CrossVersionObjectReference, annotations map[string]string, annotationKey string, defaultLevel int) int {
+	levelStr, ok := annotations[annotationKey]
+	if !ok {
+		return defaultLevel
+	}
+	level, err := strconv.Atoi(levelStr)
+	if err != nil || level < 0 {
+		logger.Info("Invalid level set as value for annotation, falling back to the level configured for the resource selector", "name", ref.Name, "kind", ref.Kind, "annotationKey", annotationKey, "value", levelStr, "level", defaultLevel)
+		return defaultLevel
+	}
+	return level
+}
+
+func createRefKey(namespace string, ref *autoscalingv1.CrossVersionObjectReference) string {
+	return ref.APIVersion + "/" + ref.Kind + "/" + namespace + "/" + ref.Name
+}
@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package scaler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	papi "github.com/gardener/dependency-watchdog/api/prober"
+	mockclient "github.com/gardener/dependency-watchdog/internal/mock/controller-runtime/client"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const selectorTestNamespace = "test-selector"
+
+func TestResolveDependentResourceInfos(t *testing.T) {
+	g := NewWithT(t)
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+		objMetaList := list.(*metav1.PartialObjectMetadataList)
+		g.Expect(objMetaList.Kind).To(Equal("DeploymentList"))
+		objMetaList.Items = []metav1.PartialObjectMetadata{
+			createObjectMetadata(kcmObjectRef.Name, nil),
+			createObjectMetadata(mcmObjectRef.Name, map[string]string{scaleUpLevelAnnotationKey: "2", scaleDownLevelAnnotationKey: "0"}),
+			createObjectMetadata(caObjectRef.Name, map[string]string{scaleUpLevelAnnotationKey: "invalid"}),
+		}
+		return nil
+	}).Times(1)
+
+	depResInfos := []papi.DependentResourceInfo{
+		createTestDeploymentDependentResourceInfo(kcmObjectRef.Name, 0, 1, nil, nil, false),
+		createTestDeploymentDependentResourceInfo(mcmObjectRef.Name, 0, 1, nil, nil, false),
+		createTestDeploymentSelectorDependentResourceInfo(1, 3),
+	}
+	depResInfos[0].Namespace = selectorTestNamespace
+	// a resource with the same name in a different namespace is a different resource
+	depResInfos[1].Namespace = "other-namespace"
+	resolvedResInfos, err := resolveDependentResourceInfos(context.Background(), mockClient, logr.Discard(), depResInfos)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resolvedResInfos).To(HaveLen(4), "a resource identified via a ref should not be added again via a selector")
+
+	g.Expect(resolvedResInfos[0]).To(Equal(depResInfos[0]))
+	g.Expect(resolvedResInfos[1]).To(Equal(depResInfos[1]))
+	g.Expect(*resolvedResInfos[2].Ref).To(Equal(mcmObjectRef))
+	g.Expect(resolvedResInfos[2].Namespace).To(Equal(selectorTestNamespace))
+	g.Expect(resolvedResInfos[2].Optional).To(BeTrue())
+	g.Expect(resolvedResInfos[2].ScaleUpInfo.Level).To(Equal(2))
+	g.Expect(resolvedResInfos[2].ScaleDownInfo.Level).To(Equal(0))
+	g.Expect(*resolvedResInfos[3].Ref).To(Equal(caObjectRef))
+	g.Expect(resolvedResInfos[3].ScaleUpInfo.Level).To(Equal(1), "an invalid level annotation should fall back to the configured level")
+	g.Expect(resolvedResInfos[3].ScaleDownInfo.Level).To(Equal(3))
+	g.Expect(depResInfos[2].ScaleUpInfo.Level).To(Equal(1), "resolving should not change the configured resource selector")
+}
+
+func TestResolveDependentResourceInfosWhenListFails(t *testing.T) {
+	g := NewWithT(t)
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("list failed")).Times(1)
+
+	depResInfos := []papi.DependentResourceInfo{createTestDeploymentSelectorDependentResourceInfo(1, 0)}
+	_, err := resolveDependentResourceInfos(context.Background(), mockClient, logr.Discard(), depResInfos)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestResolveDependentResourceInfosWithoutSelectors(t *testing.T) {
+	g := NewWithT(t)
+	depResInfos := []papi.DependentResourceInfo{
+		createTestDeploymentDependentResourceInfo(kcmObjectRef.Name, 0, 1, nil, nil, false),
+		createTestDeploymentDependentResourceInfo(mcmObjectRef.Name, 1, 0, nil, nil, false),
+	}
+	g.Expect(hasResourceSelectors(depResInfos)).To(BeFalse())
+	resolvedResInfos, err := resolveDependentResourceInfos(context.Background(), nil, logr.Discard(), depResInfos)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resolvedResInfos).To(Equal(depResInfos))
+}
+
+func createTestDeploymentSelectorDependentResourceInfo(scaleUpLevel, scaleDownLevel int) papi.DependentResourceInfo {
+	resInfo := createTestDeploymentDependentResourceInfo("", scaleUpLevel, scaleDownLevel, nil, nil, false)
+	resInfo.Ref = nil
+	resInfo.Namespace = selectorTestNamespace
+	resInfo.Selector = &papi.ResourceSelector{
+		Kind:       deploymentKind,
+		APIVersion: deploymentAPIVersion,
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"dependency-watchdog.gardener.cloud/scale-on-connectivity-loss": "true"},
+		},
+	}
+	return resInfo
+}
+
+func createObjectMetadata(name string, annotations map[string]string) metav1.PartialObjectMetadata {
+	return metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   selectorTestNamespace,
+			Annotations: annotations,
+		},
+	}
+}
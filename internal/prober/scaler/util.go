@@ -11,10 +11,13 @@ import (
 	"time"
 
 	papi "github.com/gardener/dependency-watchdog/api/prober"
+	"github.com/gardener/dependency-watchdog/internal/util"
+	"github.com/go-logr/logr"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 )
 
 // createScalableResourceInfos creates slice of scalableResourceInfo from an operation and slice of papi.DependentResourceInfo.
+// The namespace of each papi.DependentResourceInfo is expected to be already rendered, see resolveNamespaces.
 func createScalableResourceInfos(op operation, dependentResourceInfos []papi.DependentResourceInfo) []scalableResourceInfo {
 	resourceInfos := make([]scalableResourceInfo, 0, len(dependentResourceInfos))
 	for _, depResInfo := range dependentResourceInfos {
@@ -33,6 +36,7 @@ func createScalableResourceInfos(op operation, dependentResourceInfos []papi.Dep
 		}
 		resInfo := scalableResourceInfo{
 			ref:          depResInfo.Ref,
+			namespace:    depResInfo.Namespace,
 			optional:     depResInfo.Optional,
 			level:        level,
 			initialDelay: initialDelay,
@@ -87,3 +91,20 @@ func createTaskName(resInfos []scalableResourceInfo, level int) string {
 	}
 	return fmt.Sprintf("scale:level-%d:%s", level, strings.Join(resNames, "#"))
 }
+
+// resolveNamespaces renders the namespace of each papi.DependentResourceInfo using the shoot namespace and returns a copy of the
+// dependentResourceInfos with the rendered namespaces. The namespace templates are validated when the configuration is loaded, a
+// papi.DependentResourceInfo whose namespace still cannot be rendered is logged and dropped as its resources cannot be identified.
+func resolveNamespaces(logger logr.Logger, shootNamespace string, dependentResourceInfos []papi.DependentResourceInfo) []papi.DependentResourceInfo {
+	resolvedResInfos := make([]papi.DependentResourceInfo, 0, len(dependentResourceInfos))
+	for _, resInfo := range dependentResourceInfos {
+		namespace, err := util.RenderNamespace(resInfo.Namespace, shootNamespace)
+		if err != nil {
+			logger.Error(err, "Failed to render namespace of dependent resource, it will not be considered for scaling", "ref", resInfo.Ref, "selector", resInfo.Selector)
+			continue
+		}
+		resInfo.Namespace = namespace
+		resolvedResInfos = append(resolvedResInfos, resInfo)
+	}
+	return resolvedResInfos
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
 	. "github.com/onsi/gomega"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	"k8s.io/utils/pointer"
@@ -152,3 +153,23 @@ func TestCreateTaskName(t *testing.T) {
 	taskName := createTaskName(resInfos, level)
 	g.Expect(taskName).To(Equal(expectedTaskName))
 }
+
+func TestResolveNamespaces(t *testing.T) {
+	g := NewWithT(t)
+	const shootNamespace = "shoot--bingo--bango"
+	var depResInfos []papi.DependentResourceInfo
+	depResInfos = append(depResInfos, createTestDeploymentDependentResourceInfo(kcmObjectRef.Name, 0, 1, nil, nil, false))
+	depResInfos = append(depResInfos, createTestDeploymentDependentResourceInfo(mcmObjectRef.Name, 1, 0, nil, nil, false))
+	depResInfos = append(depResInfos, createTestDeploymentDependentResourceInfo(caObjectRef.Name, 1, 0, nil, nil, false))
+	depResInfos = append(depResInfos, createTestDeploymentDependentResourceInfo("invalid-namespace", 1, 0, nil, nil, false))
+	depResInfos[1].Namespace = "garden"
+	depResInfos[2].Namespace = "{{ .ShootNamespace }}-extension"
+	depResInfos[3].Namespace = "{{ .ShootNamespace"
+
+	resolvedResInfos := resolveNamespaces(logr.Discard(), shootNamespace, depResInfos)
+	g.Expect(resolvedResInfos).To(HaveLen(3), "dependent resource info with a namespace that cannot be rendered should be dropped")
+	g.Expect(resolvedResInfos[0].Namespace).To(Equal(shootNamespace))
+	g.Expect(resolvedResInfos[1].Namespace).To(Equal("garden"))
+	g.Expect(resolvedResInfos[2].Namespace).To(Equal(shootNamespace + "-extension"))
+	g.Expect(depResInfos[2].Namespace).To(Equal("{{ .ShootNamespace }}-extension"), "resolving should not change the configured namespace")
+}
@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package prober
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// Scheduler runs the probes of all probers with a bounded pool of workers instead of one goroutine per prober. It keeps the
+// probers ordered by the time at which their next probe is due, and hands each due probe to the next free worker. Once a probe
+// has completed, the next probe of the prober is scheduled after its probe interval with its jitter, as done by Prober.Run, or
+// once the back-off of the prober has ended if that is later. A prober is never probed by two workers at the same time. Closed
+// probers are dropped once their next probe is due. Before the scheduler exits, it waits for the in-flight scale flows of all
+// probers to exit, as done by Prober.Run.
+type Scheduler struct {
+	mu      sync.Mutex
+	queue   probeQueue
+	workers int
+	// scaleFlows tracks the in-flight scale flows of dropped probers which have not exited yet.
+	scaleFlows sync.WaitGroup
+	// wakeup notifies the dispatcher that a probe has been scheduled.
+	wakeup chan struct{}
+	work   chan *scheduledProbe
+	l      logr.Logger
+}
+
+// NewScheduler creates a Scheduler which runs at most the given number of probes concurrently. workers must be positive.
+func NewScheduler(workers int, logger logr.Logger) *Scheduler {
+	return &Scheduler{
+		workers: workers,
+		wakeup:  make(chan struct{}, 1),
+		work:    make(chan *scheduledProbe),
+		l:       logger,
+	}
+}
+
+// Schedule schedules the first probe of the given prober after its initial delay, spread in the same way as done by Prober.Run.
+func (s *Scheduler) Schedule(p *Prober) {
+	s.schedule(p, time.Now().Add(p.firstProbeDelay()))
+}
+
+// Start runs the workers and dispatches due probes to them until the context is cancelled. It implements manager.Runnable.
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.l.Info("Starting probe scheduler", "workers", s.workers)
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runWorker(ctx)
+		}()
+	}
+	s.dispatch(ctx)
+	wg.Wait()
+	s.waitForInFlightScaleFlows()
+	return nil
+}
+
+// waitForInFlightScaleFlows waits for the in-flight scale flows of all scheduled and dropped probers to exit. It must only be
+// called once all workers have exited.
+func (s *Scheduler) waitForInFlightScaleFlows() {
+	s.l.Info("Waiting for in-flight scale flows to exit")
+	s.mu.Lock()
+	probers := make([]*Prober, 0, s.queue.Len())
+	for _, sp := range s.queue {
+		probers = append(probers, sp.prober)
+	}
+	s.mu.Unlock()
+	for _, p := range probers {
+		p.waitForInFlightScaleFlow()
+	}
+	s.scaleFlows.Wait()
+}
+
+// dropProber drops a closed prober from the scheduler. Its in-flight scale flow, if any, is tracked until it has exited.
+func (s *Scheduler) dropProber(p *Prober) {
+	s.l.V(4).Info("Dropping closed prober from the probe scheduler", "shootNamespace", p.namespace)
+	if p.inFlightScaleFlow == nil {
+		return
+	}
+	s.scaleFlows.Add(1)
+	go func() {
+		defer s.scaleFlows.Done()
+		p.waitForInFlightScaleFlow()
+	}()
+}
+
+func (s *Scheduler) schedule(p *Prober, dueAt time.Time) {
+	s.mu.Lock()
+	heap.Push(&s.queue, &scheduledProbe{prober: p, dueAt: dueAt})
+	scheduledProbes.Set(float64(s.queue.Len()))
+	s.mu.Unlock()
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch hands each due probe to the next free worker. If all workers are busy, then due probes are delayed which is
+// reflected in the scheduling lag.
+func (s *Scheduler) dispatch(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		sp, wait := s.nextDue()
+		if sp == nil {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			var timerC <-chan time.Time
+			if wait > 0 {
+				timer.Reset(wait)
+				timerC = timer.C
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.wakeup:
+			case <-timerC:
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			// the probe is put back, so that the in-flight scale flow of its prober is waited for.
+			s.schedule(sp.prober, sp.dueAt)
+			return
+		case s.work <- sp:
+		}
+	}
+}
+
+// nextDue pops the next probe if it is due. Otherwise, it returns the duration until the next probe is due, which is zero if
+// no probe is scheduled.
+func (s *Scheduler) nextDue() (*scheduledProbe, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.queue.Len() > 0 {
+		next := s.queue[0]
+		if wait := time.Until(next.dueAt); wait > 0 {
+			return nil, wait
+		}
+		heap.Pop(&s.queue)
+		scheduledProbes.Set(float64(s.queue.Len()))
+		if next.prober.IsClosed() {
+			s.dropProber(next.prober)
+			continue
+		}
+		return next, 0
+	}
+	return nil, 0
+}
+
+func (s *Scheduler) runWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sp := <-s.work:
+			p := sp.prober
+			probeSchedulingLagSeconds.Observe(time.Since(sp.dueAt).Seconds())
+			p.probe(p.ctx)
+			s.schedule(p, p.nextProbeAt())
+		}
+	}
+}
+
+// scheduledProbe is the next probe of a prober which is due at dueAt.
+type scheduledProbe struct {
+	prober *Prober
+	dueAt  time.Time
+}
+
+// probeQueue is a priority queue of scheduled probes ordered by the time at which they are due. It implements heap.Interface.
+type probeQueue []*scheduledProbe
+
+func (q probeQueue) Len() int { return len(q) }
+
+func (q probeQueue) Less(i, j int) bool { return q[i].dueAt.Before(q[j].dueAt) }
+
+func (q probeQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *probeQueue) Push(x any) { *q = append(*q, x.(*scheduledProbe)) }
+
+func (q *probeQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return item
+}
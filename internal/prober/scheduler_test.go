@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package prober
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProbeQueueShouldOrderProbesByDueTime(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+	q := probeQueue{}
+	for _, offset := range []time.Duration{3 * time.Second, time.Second, 5 * time.Second, 2 * time.Second} {
+		heap.Push(&q, &scheduledProbe{dueAt: now.Add(offset)})
+	}
+	var dueAts []time.Time
+	for q.Len() > 0 {
+		dueAts = append(dueAts, heap.Pop(&q).(*scheduledProbe).dueAt)
+	}
+	g.Expect(dueAts).To(Equal([]time.Time{now.Add(time.Second), now.Add(2 * time.Second), now.Add(3 * time.Second), now.Add(5 * time.Second)}))
+}
+
+func TestSchedulerShouldRunProbesOfAllProbersWithBoundedWorkers(t *testing.T) {
+	g := NewWithT(t)
+	var inFlight, maxInFlight atomic.Int32
+	scheduler := NewScheduler(2, proberTestLogger)
+	var probeCounts []*atomic.Int32
+	for i := 0; i < 4; i++ {
+		p, probeCount := createScheduledProber(t, fmt.Sprintf("shoot-%d", i), func() {
+			current := inFlight.Add(1)
+			for {
+				observed := maxInFlight.Load()
+				if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			inFlight.Add(-1)
+		})
+		defer p.Close()
+		scheduler.Schedule(p)
+		probeCounts = append(probeCounts, probeCount)
+	}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = scheduler.Start(ctx)
+		close(done)
+	}()
+	for _, probeCount := range probeCounts {
+		g.Eventually(probeCount.Load).Should(BeNumerically(">=", 3))
+	}
+	cancelFn()
+	g.Eventually(done).Should(BeClosed())
+	g.Expect(maxInFlight.Load()).To(BeNumerically("<=", 2), "no more probes than workers should run concurrently")
+}
+
+func TestSchedulerShouldDropClosedProbers(t *testing.T) {
+	g := NewWithT(t)
+	scheduler := NewScheduler(1, proberTestLogger)
+	closedProber, closedProbeCount := createScheduledProber(t, "shoot-closed", func() {})
+	openProber, openProbeCount := createScheduledProber(t, "shoot-open", func() {})
+	defer openProber.Close()
+	scheduler.Schedule(closedProber)
+	scheduler.Schedule(openProber)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	go func() {
+		_ = scheduler.Start(ctx)
+	}()
+	g.Eventually(closedProbeCount.Load).Should(BeNumerically(">=", 1))
+	closedProber.Close()
+	probeCountAfterClose := closedProbeCount.Load()
+	g.Eventually(openProbeCount.Load).Should(BeNumerically(">=", 5))
+	g.Expect(closedProbeCount.Load()).To(BeNumerically("<=", probeCountAfterClose+1), "a closed prober should not be probed again after an in-flight probe")
+	g.Eventually(func() int {
+		scheduler.mu.Lock()
+		defer scheduler.mu.Unlock()
+		return scheduler.queue.Len()
+	}).Should(Equal(1))
+}
+
+func TestSchedulerShouldNotBlockWorkerWhileProberBacksOff(t *testing.T) {
+	g := NewWithT(t)
+	scheduler := NewScheduler(1, proberTestLogger)
+	mocks := createMocks(t)
+	initializeShootClientMocks(mocks, createNodes(2))
+	var throttledProbeCount atomic.Int32
+	mocks.lease.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ metav1.ListOptions) (*coordinationv1.LeaseList, error) {
+		throttledProbeCount.Add(1)
+		return nil, apierrors.NewTooManyRequests("Too many requests", 10)
+	}).AnyTimes()
+	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+	throttledProber := NewProber(context.Background(), "shoot-throttled", config, mocks.scaler, mocks.shootClientCreator, proberTestLogger)
+	defer throttledProber.Close()
+	openProber, openProbeCount := createScheduledProber(t, "shoot-open", func() {})
+	defer openProber.Close()
+	scheduler.Schedule(throttledProber)
+	scheduler.Schedule(openProber)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	go func() {
+		_ = scheduler.Start(ctx)
+	}()
+	g.Eventually(throttledProbeCount.Load).Should(BeNumerically(">=", 1))
+	g.Eventually(openProbeCount.Load).Should(BeNumerically(">=", 5), "the only worker should not be blocked by the back-off of another prober")
+	g.Expect(throttledProbeCount.Load()).To(Equal(int32(1)), "a prober should not be probed again before its back-off has ended")
+}
+
+func TestSchedulerShouldWaitForInFlightScaleFlowsOnShutdown(t *testing.T) {
+	g := NewWithT(t)
+	scheduler := NewScheduler(1, proberTestLogger)
+	mocks := createMocks(t)
+	initializeShootClientMocks(mocks, createNodes(2))
+	expectLeaseListCalls(mocks, createNodeLeases([]metav1.MicroTime{expiredLeaseRenewTime, expiredLeaseRenewTime}))
+	var scaleDownStarted, scaleDownExited atomic.Bool
+	mocks.scaler.EXPECT().ScaleDown(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ string) error {
+		scaleDownStarted.Store(true)
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		scaleDownExited.Store(true)
+		return ctx.Err()
+	}).Times(1)
+	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+	p := NewProber(context.Background(), "shoot-scaled-down", config, mocks.scaler, mocks.shootClientCreator, proberTestLogger)
+	scheduler.Schedule(p)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = scheduler.Start(ctx)
+		close(done)
+	}()
+	g.Eventually(scaleDownStarted.Load).Should(BeTrue())
+	p.Close()
+	cancelFn()
+	g.Eventually(done).Should(BeClosed())
+	g.Expect(scaleDownExited.Load()).To(BeTrue(), "the scheduler should only exit once the in-flight scale flow has exited")
+}
+
+// createScheduledProber creates a prober whose node leases are all valid and which has nothing to scale up. onProbe is
+// called with every lease probe, the returned counter counts the lease probes.
+func createScheduledProber(t *testing.T, namespace string, onProbe func()) (*Prober, *atomic.Int32) {
+	leaseList := createNodeLeases([]metav1.MicroTime{nonExpiredLeaseRenewTime, nonExpiredLeaseRenewTime})
+	mocks := createMocks(t)
+	initializeShootClientMocks(mocks, createNodes(len(leaseList.Items)))
+	var count atomic.Int32
+	mocks.lease.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ metav1.ListOptions) (*coordinationv1.LeaseList, error) {
+		onProbe()
+		count.Add(1)
+		return leaseList, nil
+	}).AnyTimes()
+	mocks.scaler.EXPECT().IsScaledDown(gomock.Any()).Return(false, nil).MaxTimes(1)
+	config := createConfig(testProbeInterval, metav1.Duration{Duration: time.Microsecond}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+	return NewProber(context.Background(), namespace, config, mocks.scaler, mocks.shootClientCreator, proberTestLogger), &count
+}
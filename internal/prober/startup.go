@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package prober
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// StartupRamp spreads the first probes of the probers which are started shortly after DWD has started or has become the leader.
+// At that time the cluster controller reconciles all clusters at once, and without a ramp all probers would fire their first
+// probes together. The ramp starts once the first prober is created, and it is shared by all probers.
+type StartupRamp struct {
+	duration  time.Duration
+	once      sync.Once
+	startedAt time.Time
+}
+
+// NewStartupRamp creates a StartupRamp which spreads the first probes over the given duration.
+func NewStartupRamp(duration time.Duration) *StartupRamp {
+	return &StartupRamp{duration: duration}
+}
+
+// delay returns the delay of the first probe of the prober for the given namespace which is created at the given time. The delay
+// is deterministic for a namespace, and it is zero once the ramp is over.
+func (r *StartupRamp) delay(namespace string, now time.Time) time.Duration {
+	r.once.Do(func() {
+		r.startedAt = now
+	})
+	rampDelay := r.startedAt.Add(spreadOffset(namespace, r.duration)).Sub(now)
+	return max(rampDelay, 0)
+}
+
+// spreadOffset deterministically maps the namespace to an offset within [0, window) by hashing it, so that the first probes
+// of probers which are created at the same time are spread across the window.
+func spreadOffset(namespace string, window time.Duration) time.Duration {
+	if window <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(namespace))
+	return time.Duration(h.Sum64() % uint64(window))
+}
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package prober
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestSpreadOffsetShouldBeDeterministicAndWithinWindow(t *testing.T) {
+	g := NewWithT(t)
+	window := 10 * time.Second
+	offsets := sets.New[time.Duration]()
+	for i := 0; i < 100; i++ {
+		namespace := fmt.Sprintf("shoot--project--name-%d", i)
+		offset := spreadOffset(namespace, window)
+		g.Expect(offset).To(BeNumerically(">=", 0))
+		g.Expect(offset).To(BeNumerically("<", window))
+		g.Expect(spreadOffset(namespace, window)).To(Equal(offset), "the offset of a namespace should not change")
+		offsets.Insert(offset.Truncate(time.Second))
+	}
+	g.Expect(offsets.Len()).To(BeNumerically(">", 5), "the offsets should be spread across the window")
+	g.Expect(spreadOffset("shoot--project--name", 0)).To(BeZero())
+}
+
+func TestStartupRampShouldOnlyDelayProbersCreatedDuringRamp(t *testing.T) {
+	g := NewWithT(t)
+	ramp := NewStartupRamp(time.Minute)
+	now := time.Now()
+
+	g.Expect(ramp.delay("shoot--a", now)).To(Equal(spreadOffset("shoot--a", time.Minute)))
+	later := now.Add(10 * time.Second)
+	g.Expect(ramp.delay("shoot--b", later)).To(Equal(max(spreadOffset("shoot--b", time.Minute)-10*time.Second, 0)), "the ramp should start with the first prober")
+	g.Expect(ramp.delay("shoot--a", now.Add(time.Minute))).To(BeZero(), "no prober should be delayed once the ramp is over")
+}
+
+func TestFirstProbeDelayShouldBeSpreadAcrossProbeIntervalOrStartupRamp(t *testing.T) {
+	g := NewWithT(t)
+	config := createConfig(metav1.Duration{Duration: 10 * time.Second}, metav1.Duration{Duration: 30 * time.Second}, metav1.Duration{Duration: 40 * time.Second}, 0.2)
+
+	p := NewProber(context.Background(), "shoot--project--name", config, nil, nil, proberTestLogger)
+	g.Expect(p.firstProbeDelay()).To(Equal(30*time.Second + spreadOffset("shoot--project--name", 10*time.Second)))
+
+	rampedProber := NewProber(context.Background(), "shoot--project--name", config, nil, nil, proberTestLogger, WithStartupRamp(NewStartupRamp(time.Hour)))
+	g.Expect(rampedProber.firstProbeDelay()).To(Equal(30*time.Second + spreadOffset("shoot--project--name", time.Hour)))
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -94,16 +95,17 @@ func createTransportWithDisabledKeepAlive(config *rest.Config) (*http.Transport,
 	return transport, nil
 }
 
-// CreateScalesGetter Creates a new ScalesGetter given the config
-func CreateScalesGetter(config *rest.Config) (scale.ScalesGetter, error) {
+// CreateScalesGetter Creates a new ScalesGetter given the config. It also returns the ScaleKindResolver used by the ScalesGetter,
+// which resolves the scale subresource of a resource via discovery, so that it can be reused without another discovery client.
+func CreateScalesGetter(config *rest.Config) (scale.ScalesGetter, scale.ScaleKindResolver, error) {
 	clientSet, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	discoveryClient := clientSet.Discovery()
 	resolver := scale.NewDiscoveryScaleKindResolver(discoveryClient)
 	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
-	return scale.New(clientSet.RESTClient(), mapper, dynamic.LegacyAPIPathResolverFunc, resolver), nil
+	return scale.New(clientSet.RESTClient(), mapper, dynamic.LegacyAPIPathResolverFunc, resolver), resolver, nil
 }
 
 // GetScaleResource returns a kubernetes scale subresource.
@@ -136,7 +138,7 @@ func getGroupResource(client client.Client, logger logr.Logger, resourceRef *aut
 }
 
 // GetResourceAnnotations gets the annotations for a resource identified by resourceRef withing the given namespace.
-func GetResourceAnnotations(ctx context.Context, client client.Client, namespace string, resourceRef *autoscalingv1.CrossVersionObjectReference) (map[string]string, error) {
+func GetResourceAnnotations(ctx context.Context, client client.Reader, namespace string, resourceRef *autoscalingv1.CrossVersionObjectReference) (map[string]string, error) {
 	partialObjMeta := &metav1.PartialObjectMetadata{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       resourceRef.Kind,
@@ -150,6 +152,24 @@ func GetResourceAnnotations(ctx context.Context, client client.Client, namespace
 	return partialObjMeta.Annotations, nil
 }
 
+// ListResourcesMetadata lists the metadata of all resources of the given kind and apiVersion within the given namespace which match the labelSelector.
+func ListResourcesMetadata(ctx context.Context, cl client.Client, namespace string, apiVersion string, kind string, labelSelector *metav1.LabelSelector) ([]metav1.PartialObjectMetadata, error) {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	partialObjMetaList := &metav1.PartialObjectMetadataList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       kind + "List",
+			APIVersion: apiVersion,
+		},
+	}
+	if err = cl.List(ctx, partialObjMetaList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	return partialObjMetaList.Items, nil
+}
+
 // PatchResourceAnnotations patches the resource annotation with patchBytes. It uses StrategicMergePatchType strategy so the consumers should only provide changes to the annotations.
 func PatchResourceAnnotations(ctx context.Context, cl client.Client, namespace string, resourceRef *autoscalingv1.CrossVersionObjectReference, patchBytes []byte) error {
 	partialObjMeta := &metav1.PartialObjectMetadata{
@@ -165,9 +185,19 @@ func PatchResourceAnnotations(ctx context.Context, cl client.Client, namespace s
 	return cl.Patch(ctx, partialObjMeta, client.RawPatch(types.MergePatchType, patchBytes))
 }
 
-// GetResourceReadyReplicas gets spec.replicas for any resource identified via resourceRef withing the given namespace.
-// It is an error if there is no spec.replicas or if there is an error fetching the resource.
-func GetResourceReadyReplicas(ctx context.Context, cli client.Client, namespace string, resourceRef *autoscalingv1.CrossVersionObjectReference) (int32, error) {
+// GetResourceReadyReplicas gets status.readyReplicas for any resource identified via resourceRef withing the given namespace.
+// It is an error if there is an error fetching the resource.
+func GetResourceReadyReplicas(ctx context.Context, cli client.Reader, namespace string, resourceRef *autoscalingv1.CrossVersionObjectReference) (int32, error) {
+	return getResourceReplicasField(ctx, cli, namespace, resourceRef, "status", "readyReplicas")
+}
+
+// GetResourceReplicas gets spec.replicas for any resource identified via resourceRef withing the given namespace.
+// It is an error if there is an error fetching the resource.
+func GetResourceReplicas(ctx context.Context, cli client.Reader, namespace string, resourceRef *autoscalingv1.CrossVersionObjectReference) (int32, error) {
+	return getResourceReplicasField(ctx, cli, namespace, resourceRef, "spec", "replicas")
+}
+
+func getResourceReplicasField(ctx context.Context, cli client.Reader, namespace string, resourceRef *autoscalingv1.CrossVersionObjectReference, fields ...string) (int32, error) {
 	resObj := unstructured.Unstructured{}
 
 	groupVersion, err := schema.ParseGroupVersion(resourceRef.APIVersion)
@@ -183,7 +213,7 @@ func GetResourceReadyReplicas(ctx context.Context, cli client.Client, namespace
 	if err != nil {
 		return 0, err
 	}
-	readyReplicas, found, err := unstructured.NestedInt64(resObj.Object, "status", "readyReplicas")
+	replicas, found, err := unstructured.NestedInt64(resObj.Object, fields...)
 	if !found {
 		return 0, nil
 	}
@@ -191,7 +221,7 @@ func GetResourceReadyReplicas(ctx context.Context, cli client.Client, namespace
 		return 0, err
 	}
 
-	return int32(readyReplicas), nil
+	return int32(replicas), nil
 }
 
 // CreateClientSetFromRestConfig creates a kubernetes.Clientset from rest.Config.
@@ -202,3 +232,16 @@ func CreateClientSetFromRestConfig(config *rest.Config) (*kubernetes.Clientset,
 	}
 	return clientset, nil
 }
+
+// GetAuthenticatedUsername returns the username with which the given rest.Config is authenticated by the API server.
+func GetAuthenticatedUsername(ctx context.Context, config *rest.Config) (string, error) {
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", err
+	}
+	selfSubjectReview, err := clientSet.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err != nil {
+		return "", err
+	}
+	return selfSubjectReview.Status.UserInfo.Username, nil
+}
@@ -82,6 +82,7 @@ func TestSuitForK8sHelper(t *testing.T) {
 		{"get ready replicas for a resource that does not exist", testGetReadyReplicasForNonExistingResource},
 		{"get ready replicas for a resource with zero spec.replicas", testGetReadyReplicasForResourceWithZeroReplicas},
 		{"get ready replicas for a resource with spec.replicas greater than zero", testGetReadyReplicasForResourceWithNonZeroReplicas},
+		{"get spec replicas for a resource", testGetResourceReplicas},
 		{"get resource annotations", testGetResourceAnnotations},
 		{"get resource annotations with no annotations set", testGetResourceAnnotationsWhenNoneExists},
 		{"patch resource annotations", testPatchResourceAnnotations},
@@ -153,9 +154,10 @@ func testCreateScalesGetter(t *testing.T) {
 	g := NewWithT(t)
 	config := getRestConfig(g, kubeConfigPath)
 
-	scalesGetter, err := CreateScalesGetter(config)
+	scalesGetter, scaleKindResolver, err := CreateScalesGetter(config)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(scalesGetter).ToNot(BeNil())
+	g.Expect(scaleKindResolver).ToNot(BeNil())
 }
 
 func testGetScaleResource(t *testing.T) {
@@ -165,7 +167,7 @@ func testGetScaleResource(t *testing.T) {
 	)
 	g := NewWithT(t)
 	ctx := context.Background()
-	scalesGetter, err := CreateScalesGetter(restConfig)
+	scalesGetter, _, err := CreateScalesGetter(restConfig)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(scalesGetter).ToNot(BeNil())
 
@@ -191,7 +193,7 @@ func testGetScaleResource(t *testing.T) {
 func testGetScaleResourceForUnsupportedGKV(t *testing.T) {
 	g := NewWithT(t)
 	ctx := context.Background()
-	scalesGetter, err := CreateScalesGetter(restConfig)
+	scalesGetter, _, err := CreateScalesGetter(restConfig)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(scalesGetter).ToNot(BeNil())
 
@@ -263,6 +265,25 @@ func testGetReadyReplicasForResourceWithNonZeroReplicas(t *testing.T) {
 	}, "30s").Should(Equal(int32(1)))
 }
 
+func testGetResourceReplicas(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	const namespace = "default"
+	d, cleanup := getDeploymentFromFile(ctx, g, deploymentPath)
+	defer cleanup(g)
+	d.Spec.Replicas = pointer.Int32(2)
+	err := k8sClient.Create(ctx, d)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	replicas, err := GetResourceReplicas(ctx, k8sClient, namespace, &autoscalingv1.CrossVersionObjectReference{
+		Kind:       "Deployment",
+		Name:       d.Name,
+		APIVersion: "apps/v1",
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(replicas).To(Equal(int32(2)))
+}
+
 func testGetResourceAnnotations(t *testing.T) {
 	g := NewWithT(t)
 	ctx := context.Background()
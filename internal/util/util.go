@@ -5,10 +5,15 @@
 package util
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"os"
+	"strings"
+	"text/template"
 	"time"
 
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/yaml"
 )
 
@@ -50,3 +55,31 @@ func GetValOrDefault[T any](val *T, defaultVal T) *T {
 	}
 	return val
 }
+
+// namespaceTemplateData is the data which is available when rendering a namespace template.
+type namespaceTemplateData struct {
+	// ShootNamespace is the namespace of the shoot control plane.
+	ShootNamespace string
+}
+
+// RenderNamespace renders the given namespace template using the shootNamespace. The template can refer to the shoot namespace via
+// {{ .ShootNamespace }}. If the template is empty then the shootNamespace is returned. An error is returned if the template cannot be
+// parsed or if the rendered namespace is not a valid namespace name.
+func RenderNamespace(namespaceTemplate string, shootNamespace string) (string, error) {
+	if strings.TrimSpace(namespaceTemplate) == "" {
+		return shootNamespace, nil
+	}
+	t, err := template.New("namespace").Option("missingkey=error").Parse(namespaceTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse namespace template %q: %w", namespaceTemplate, err)
+	}
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, namespaceTemplateData{ShootNamespace: shootNamespace}); err != nil {
+		return "", fmt.Errorf("failed to render namespace template %q: %w", namespaceTemplate, err)
+	}
+	namespace := buf.String()
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return "", fmt.Errorf("namespace %q rendered from template %q is not a valid namespace name: %s", namespace, namespaceTemplate, strings.Join(errs, ", "))
+	}
+	return namespace, nil
+}
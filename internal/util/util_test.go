@@ -91,3 +91,31 @@ func TestFillDefaultIfNil(t *testing.T) {
 	testFloat = GetValOrDefault(testFloat, 2.0)
 	g.Expect(*testFloat).To(Equal(1.0))
 }
+
+func TestRenderNamespace(t *testing.T) {
+	g := NewWithT(t)
+	table := []struct {
+		description       string
+		namespaceTemplate string
+		expectedNamespace string
+		expectError       bool
+	}{
+		{"empty template should result in the shoot namespace", "", "shoot--bingo--bango", false},
+		{"fixed namespace should be returned as is", "garden", "garden", false},
+		{"template referring to the shoot namespace should be rendered", "{{ .ShootNamespace }}-extension", "shoot--bingo--bango-extension", false},
+		{"template with a syntax error should fail", "{{ .ShootNamespace", "", true},
+		{"template referring to unknown data should fail", "{{ .Unknown }}", "", true},
+		{"template rendering an invalid namespace name should fail", "{{ .ShootNamespace }}_extension", "", true},
+	}
+	for _, entry := range table {
+		t.Run(entry.description, func(t *testing.T) {
+			namespace, err := RenderNamespace(entry.namespaceTemplate, "shoot--bingo--bango")
+			if entry.expectError {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(namespace).To(Equal(entry.expectedNamespace))
+		})
+	}
+}
@@ -13,8 +13,10 @@ import (
 
 	multierr "github.com/hashicorp/go-multierror"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/scale"
 )
 
 // Validator is a struct to store all validation errors.
@@ -84,3 +86,23 @@ func (v *Validator) ResourceRefMustBeValid(resourceRef *autoscalingv1.CrossVersi
 	}
 	return scheme.Recognizes(gvk)
 }
+
+// ScaleSubresourceMustBeSupported validates that the resource kind identified by the given resourceRef is served by the API server
+// and that it exposes a scale subresource. The mapper and the resolver are expected to be backed by the discovery of the API server.
+func (v *Validator) ScaleSubresourceMustBeSupported(resourceRef *autoscalingv1.CrossVersionObjectReference, mapper meta.RESTMapper, resolver scale.ScaleKindResolver) bool {
+	gv, err := schema.ParseGroupVersion(resourceRef.APIVersion)
+	if err != nil {
+		v.Error = multierr.Append(v.Error, err)
+		return false
+	}
+	mapping, err := mapper.RESTMapping(schema.GroupKind{Group: gv.Group, Kind: resourceRef.Kind}, gv.Version)
+	if err != nil {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("kind %s of apiVersion %s is not served by the API server: %w", resourceRef.Kind, resourceRef.APIVersion, err))
+		return false
+	}
+	if _, err = resolver.ScaleForResource(mapping.Resource); err != nil {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("resource %s does not expose a scale subresource: %w", mapping.Resource.String(), err))
+		return false
+	}
+	return true
+}
@@ -7,14 +7,18 @@
 package util
 
 import (
+	"fmt"
 	"testing"
 
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 func TestMustNotBeEmpty(t *testing.T) {
@@ -113,3 +117,42 @@ func TestResourceRefMustBeValid(t *testing.T) {
 		g.Expect(entry.result).To(Equal(actualResult))
 	}
 }
+
+func TestScaleSubresourceMustBeSupported(t *testing.T) {
+	g := NewWithT(t)
+
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{appsv1.SchemeGroupVersion, corev1.SchemeGroupVersion})
+	mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	resolver := testScaleKindResolver{scalableResources: sets.New(appsv1.SchemeGroupVersion.WithResource("deployments"))}
+
+	tests := []struct {
+		resourceRef autoscalingv1.CrossVersionObjectReference
+		result      bool
+	}{
+		{autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "d1", APIVersion: "apps/v1"}, true},
+		{autoscalingv1.CrossVersionObjectReference{Kind: "Depoyment", Name: "d2", APIVersion: "apps/v1"}, false},
+		{autoscalingv1.CrossVersionObjectReference{Kind: "ConfigMap", Name: "c1", APIVersion: "v1"}, false},
+		{autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "d3", APIVersion: "core/apps/v1"}, false},
+	}
+
+	for _, entry := range tests {
+		v := Validator{}
+		actualResult := v.ScaleSubresourceMustBeSupported(&entry.resourceRef, mapper, resolver)
+		g.Expect(actualResult).To(Equal(entry.result))
+		if !actualResult {
+			g.Expect(v.Error).To(HaveOccurred())
+		}
+	}
+}
+
+type testScaleKindResolver struct {
+	scalableResources sets.Set[schema.GroupVersionResource]
+}
+
+func (r testScaleKindResolver) ScaleForResource(resource schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	if !r.scalableResources.Has(resource) {
+		return schema.GroupVersionKind{}, fmt.Errorf("could not find scale subresource for %s", resource.String())
+	}
+	return schema.GroupVersionKind{Group: "autoscaling", Version: "v1", Kind: "Scale"}, nil
+}
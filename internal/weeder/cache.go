@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package weeder
+
+import (
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetCacheOptions returns the options for the cache of the weeder controller manager. If namespaces are explicitly included,
+// then only the objects in these namespaces are cached, which also restricts the pods which are watched by the weeders.
+// As the weeders share a single watch on pods, the pods are the largest part of the cache on a seed. Therefore, only the pods
+// which can match any of the configured pod selectors are cached, and only the parts of a pod which are read by the weeders are kept.
+func GetCacheOptions(c *wapi.Config) cache.Options {
+	opts := cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&v1.Pod{}: {Label: getPodCacheSelector(c), Transform: stripPod},
+		},
+	}
+	if c.NamespaceSelection == nil || len(c.NamespaceSelection.Include) == 0 {
+		return opts
+	}
+	defaultNamespaces := make(map[string]cache.Config, len(c.NamespaceSelection.Include))
+	for _, namespace := range c.NamespaceSelection.Include {
+		defaultNamespaces[namespace] = cache.Config{}
+	}
+	opts.DefaultNamespaces = defaultNamespaces
+	return opts
+}
+
+// getPodCacheSelector returns a selector which matches all pods matched by any of the configured pod selectors. As a selector cannot
+// express a union of selectors, it only consists of the labels which are required by all pod selectors, where a label is restricted
+// to the union of its values if all pod selectors restrict its values. A pod matched by the selector thus does not necessarily match
+// any of the pod selectors. If the pod selectors do not have a label in common, then nil is returned and all pods are cached.
+func getPodCacheSelector(c *wapi.Config) labels.Selector {
+	var common map[string]sets.Set[string]
+	for _, ps := range getPodSelectors(c) {
+		required, ok := getRequiredLabels(ps)
+		if !ok {
+			continue
+		}
+		if common == nil {
+			common = required
+			continue
+		}
+		for key, values := range common {
+			otherValues, found := required[key]
+			switch {
+			case !found:
+				delete(common, key)
+			case values == nil || otherValues == nil:
+				common[key] = nil
+			default:
+				values.Insert(otherValues.UnsortedList()...)
+			}
+		}
+	}
+	if len(common) == 0 {
+		return nil
+	}
+	selector := labels.NewSelector()
+	for key, values := range common {
+		op, vals := selection.In, sets.List(values)
+		if values == nil {
+			op, vals = selection.Exists, nil
+		}
+		requirement, err := labels.NewRequirement(key, op, vals)
+		if err != nil {
+			// a label which no pod can match, e.g. as it is restricted to different values within a pod selector, restricts nothing.
+			continue
+		}
+		selector = selector.Add(*requirement)
+	}
+	return selector
+}
+
+// getPodSelectors returns the pod selectors of all services and dependants.
+func getPodSelectors(c *wapi.Config) []*metav1.LabelSelector {
+	var podSelectors []*metav1.LabelSelector
+	for _, ds := range c.ServicesAndDependantSelectors {
+		podSelectors = append(podSelectors, ds.PodSelectors...)
+	}
+	for _, dependant := range c.Dependants {
+		podSelectors = append(podSelectors, dependant.PodSelectors...)
+	}
+	return podSelectors
+}
+
+// getRequiredLabels returns the labels which a pod must have to match the given pod selector, along with the values which they
+// can have, where nil means any value. It returns false if the pod selector matches no pod, which is the case for a nil selector.
+func getRequiredLabels(ps *metav1.LabelSelector) (map[string]sets.Set[string], bool) {
+	if ps == nil {
+		return nil, false
+	}
+	required := make(map[string]sets.Set[string])
+	restrict := func(key string, values ...string) {
+		if current := required[key]; current != nil {
+			required[key] = current.Intersection(sets.New(values...))
+			return
+		}
+		required[key] = sets.New(values...)
+	}
+	for key, value := range ps.MatchLabels {
+		restrict(key, value)
+	}
+	for _, expr := range ps.MatchExpressions {
+		switch expr.Operator {
+		case metav1.LabelSelectorOpIn:
+			restrict(expr.Key, expr.Values...)
+		case metav1.LabelSelectorOpExists:
+			if _, found := required[expr.Key]; !found {
+				required[expr.Key] = nil
+			}
+		}
+	}
+	return required, true
+}
+
+// stripPod is a cache transform which drops the parts of a pod which are not read by the weeders, i.e. its managed fields,
+// annotations and spec. The weeders only read the metadata, the owner references and the status of a pod.
+func stripPod(obj interface{}) (interface{}, error) {
+	if pod, ok := obj.(*v1.Pod); ok {
+		pod.ManagedFields = nil
+		pod.Annotations = nil
+		pod.Spec = v1.PodSpec{}
+	}
+	return obj, nil
+}
@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"testing"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+func TestGetCacheOptionsShouldOnlyRestrictCacheToIncludedNamespaces(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(GetCacheOptions(testWeederConfig).DefaultNamespaces).To(BeNil())
+
+	config := &wapi.Config{NamespaceSelection: &wapi.NamespaceSelection{Exclude: []string{"garden"}}}
+	g.Expect(GetCacheOptions(config).DefaultNamespaces).To(BeNil())
+
+	config.NamespaceSelection.Include = []string{"shoot--foo", "shoot--bar"}
+	g.Expect(GetCacheOptions(config).DefaultNamespaces).To(Equal(map[string]cache.Config{"shoot--foo": {}, "shoot--bar": {}}))
+}
+
+func TestGetCacheOptionsShouldRestrictCachedPods(t *testing.T) {
+	g := NewWithT(t)
+	config := &wapi.Config{
+		ServicesAndDependantSelectors: map[string]wapi.DependantSelectors{
+			"etcd-main-client": {PodSelectors: []*metav1.LabelSelector{
+				{MatchLabels: map[string]string{"gardener.cloud/role": "controlplane", "role": "apiserver"}},
+			}},
+			"kube-apiserver": {PodSelectors: []*metav1.LabelSelector{
+				{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "gardener.cloud/role", Operator: metav1.LabelSelectorOpIn, Values: []string{"controlplane"}},
+					{Key: "role", Operator: metav1.LabelSelectorOpIn, Values: []string{"controller-manager", "scheduler"}},
+				}},
+			}},
+		},
+	}
+	var podCache cache.ByObject
+	for obj, byObject := range GetCacheOptions(config).ByObject {
+		g.Expect(obj).To(BeAssignableToTypeOf(&v1.Pod{}))
+		podCache = byObject
+	}
+	g.Expect(podCache.Label).ToNot(BeNil())
+	g.Expect(podCache.Label.String()).To(Equal("gardener.cloud/role in (controlplane),role in (apiserver,controller-manager,scheduler)"))
+	g.Expect(podCache.Transform).ToNot(BeNil())
+}
+
+func TestGetPodCacheSelector(t *testing.T) {
+	controlPlane := map[string]string{"gardener.cloud/role": "controlplane"}
+	testCases := []struct {
+		name         string
+		podSelectors []*metav1.LabelSelector
+		dependants   []*metav1.LabelSelector
+		matched      []labels.Set
+		notMatched   []labels.Set
+		all          bool
+	}{
+		{name: "no pod selectors should cache all pods", all: true},
+		{
+			name: "labels which are not required by all pod selectors should be dropped",
+			podSelectors: []*metav1.LabelSelector{
+				{MatchLabels: map[string]string{"gardener.cloud/role": "controlplane", "role": "apiserver"}},
+				{MatchLabels: controlPlane},
+			},
+			matched:    []labels.Set{controlPlane, {"gardener.cloud/role": "controlplane", "role": "scheduler"}},
+			notMatched: []labels.Set{{"role": "apiserver"}, {"gardener.cloud/role": "shoot"}},
+		},
+		{
+			name: "a label which may have any value should only be required to exist",
+			podSelectors: []*metav1.LabelSelector{
+				{MatchLabels: controlPlane},
+				{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "gardener.cloud/role", Operator: metav1.LabelSelectorOpExists}}},
+			},
+			matched:    []labels.Set{controlPlane, {"gardener.cloud/role": "shoot"}},
+			notMatched: []labels.Set{{"role": "apiserver"}},
+		},
+		{
+			name:         "labels which are excluded should not restrict the cached pods",
+			podSelectors: []*metav1.LabelSelector{{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "role", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"apiserver"}}}}},
+			all:          true,
+		},
+		{
+			name:         "pod selectors without a label in common should cache all pods",
+			podSelectors: []*metav1.LabelSelector{{MatchLabels: controlPlane}},
+			dependants:   []*metav1.LabelSelector{{MatchLabels: map[string]string{"app": "etcd"}}},
+			all:          true,
+		},
+		{
+			name:         "pod selectors of dependants should be considered",
+			podSelectors: []*metav1.LabelSelector{{MatchLabels: controlPlane}},
+			dependants:   []*metav1.LabelSelector{{MatchLabels: map[string]string{"gardener.cloud/role": "monitoring"}}},
+			matched:      []labels.Set{controlPlane, {"gardener.cloud/role": "monitoring"}},
+			notMatched:   []labels.Set{{"gardener.cloud/role": "shoot"}},
+		},
+	}
+
+	for _, entry := range testCases {
+		t.Run(entry.name, func(t *testing.T) {
+			g := NewWithT(t)
+			config := &wapi.Config{ServicesAndDependantSelectors: map[string]wapi.DependantSelectors{"service": {PodSelectors: entry.podSelectors}}}
+			if entry.dependants != nil {
+				config.Dependants = []wapi.Dependant{{Name: "dependant", DependantSelectors: wapi.DependantSelectors{PodSelectors: entry.dependants}}}
+			}
+			selector := getPodCacheSelector(config)
+			if entry.all {
+				g.Expect(selector).To(BeNil())
+				return
+			}
+			g.Expect(selector).ToNot(BeNil())
+			for _, podLabels := range entry.matched {
+				g.Expect(selector.Matches(podLabels)).To(BeTrue(), "pod with labels %v should be cached", podLabels)
+			}
+			for _, podLabels := range entry.notMatched {
+				g.Expect(selector.Matches(podLabels)).To(BeFalse(), "pod with labels %v should not be cached", podLabels)
+			}
+		})
+	}
+}
+
+func TestStripPodShouldOnlyKeepFieldsReadByWeeders(t *testing.T) {
+	g := NewWithT(t)
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "pod",
+			Namespace:       "default",
+			Labels:          map[string]string{"role": "apiserver"},
+			Annotations:     map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+			ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "rs"}},
+		},
+		Spec:   v1.PodSpec{Containers: []v1.Container{{Name: "container"}}},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+	obj, err := stripPod(pod)
+	g.Expect(err).ToNot(HaveOccurred())
+	stripped := obj.(*v1.Pod)
+	g.Expect(stripped.ManagedFields).To(BeNil())
+	g.Expect(stripped.Annotations).To(BeNil())
+	g.Expect(stripped.Spec).To(Equal(v1.PodSpec{}))
+	g.Expect(stripped.Labels).To(Equal(map[string]string{"role": "apiserver"}))
+	g.Expect(stripped.OwnerReferences).To(HaveLen(1))
+	g.Expect(stripped.Status.Phase).To(Equal(v1.PodRunning))
+}
@@ -5,6 +5,9 @@
 package weeder
 
 import (
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	wapi "github.com/gardener/dependency-watchdog/api/weeder"
@@ -13,11 +16,14 @@ import (
 	multierr "github.com/hashicorp/go-multierror"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 const (
 	// defaultWatchDuration is the default duration after which the watch expires.
 	defaultWatchDuration = 5 * time.Minute
+	// defaultEvictionRetryInterval is the default interval after which a rejected eviction is retried.
+	defaultEvictionRetryInterval = 10 * time.Second
 )
 
 // LoadConfig reads the weeder configuration from a file, unmarshalls it, fills in the default values and
@@ -38,18 +44,176 @@ func LoadConfig(filename string) (*wapi.Config, error) {
 func validate(c *wapi.Config) error {
 	v := new(util.Validator)
 	// Check the mandatory config parameters for which a default will not be set
-	v.MustNotBeEmpty("serviceAndDependantSelectors", c.ServicesAndDependantSelectors)
-	for _, ds := range c.ServicesAndDependantSelectors {
-		v.MustNotBeEmpty("podSelectors", ds.PodSelectors)
-		for _, selector := range ds.PodSelectors {
-			_, err := metav1.LabelSelectorAsSelector(selector)
-			if err != nil {
-				v.Error = multierr.Append(v.Error, err)
-				continue
+	if len(c.Dependants) == 0 {
+		v.MustNotBeEmpty("serviceAndDependantSelectors", c.ServicesAndDependantSelectors)
+	}
+	for svcName, ds := range c.ServicesAndDependantSelectors {
+		validateDependantSelectors(v, "service "+svcName, ds)
+	}
+	validateDependants(v, c.Dependants)
+	validateEviction(v, c.Eviction)
+	validateNamespaceSelection(v, c.NamespaceSelection)
+	validateDependantServices(v, c.ServicesAndDependantSelectors)
+	return v.Error
+}
+
+// validateDependantSelectors validates the pod selectors, the weedable conditions, the throttling and the action of the
+// dependant pods of the given owner, which is either a service or a dependant.
+func validateDependantSelectors(v *util.Validator, owner string, ds wapi.DependantSelectors) {
+	v.MustNotBeEmpty("podSelectors", ds.PodSelectors)
+	for _, selector := range ds.PodSelectors {
+		_, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			v.Error = multierr.Append(v.Error, err)
+			continue
+		}
+	}
+	if ds.WatchDuration != nil && ds.WatchDuration.Duration <= 0 {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("watchDuration for %s must be positive, found %s", owner, ds.WatchDuration.Duration))
+	}
+	validateWeedableConditions(v, owner, ds.WeedableConditions)
+	validateThrottling(v, owner, ds.Throttling)
+	if *ds.Action != wapi.WeederActionDeletePod && *ds.Action != wapi.WeederActionRolloutRestart {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("action %s for %s must be one of %s, %s", *ds.Action, owner, wapi.WeederActionDeletePod, wapi.WeederActionRolloutRestart))
+	}
+}
+
+// validateDependants validates that every dependant has a unique name and at least one required service, and that it does not
+// configure dependant services, which are only supported for services.
+func validateDependants(v *util.Validator, dependants []wapi.Dependant) {
+	names := sets.New[string]()
+	for _, dependant := range dependants {
+		if !v.MustNotBeEmpty("dependants.name", dependant.Name) {
+			continue
+		}
+		if names.Has(dependant.Name) {
+			v.Error = multierr.Append(v.Error, fmt.Errorf("dependant name %s must be unique", dependant.Name))
+		}
+		names.Insert(dependant.Name)
+		owner := "dependant " + dependant.Name
+		if len(dependant.RequiredServices) == 0 {
+			v.Error = multierr.Append(v.Error, fmt.Errorf("requiredServices for %s must not be empty", owner))
+		}
+		if len(dependant.DependantServices) > 0 {
+			v.Error = multierr.Append(v.Error, fmt.Errorf("dependantServices are not supported for %s", owner))
+		}
+		validateDependantSelectors(v, owner, dependant.DependantSelectors)
+	}
+}
+
+// validateDependantServices validates that all dependant services are configured services and that the dependencies between
+// the services do not contain a cycle.
+func validateDependantServices(v *util.Validator, servicesAndDependantSelectors map[string]wapi.DependantSelectors) {
+	for svcName, ds := range servicesAndDependantSelectors {
+		for _, dependantService := range ds.DependantServices {
+			if _, ok := servicesAndDependantSelectors[dependantService]; !ok {
+				v.Error = multierr.Append(v.Error, fmt.Errorf("dependant service %s of service %s is not configured in servicesAndDependantSelectors", dependantService, svcName))
 			}
 		}
 	}
-	return v.Error
+	if cycle := findDependencyCycle(servicesAndDependantSelectors); cycle != nil {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("dependant services must not contain a cycle, found %s", strings.Join(cycle, " -> ")))
+	}
+}
+
+// findDependencyCycle does a depth-first search along the dependant services and returns the first cycle it finds, or nil
+// if there is no cycle. The services are visited in sorted order so that the same cycle is reported every time.
+func findDependencyCycle(servicesAndDependantSelectors map[string]wapi.DependantSelectors) []string {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int, len(servicesAndDependantSelectors))
+	var path []string
+	var visit func(svcName string) []string
+	visit = func(svcName string) []string {
+		switch state[svcName] {
+		case inProgress:
+			start := slices.Index(path, svcName)
+			return append(slices.Clone(path[start:]), svcName)
+		case done:
+			return nil
+		}
+		state[svcName] = inProgress
+		path = append(path, svcName)
+		for _, dependantService := range servicesAndDependantSelectors[svcName].DependantServices {
+			if cycle := visit(dependantService); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[svcName] = done
+		return nil
+	}
+	for _, svcName := range sets.List(sets.KeySet(servicesAndDependantSelectors)) {
+		if cycle := visit(svcName); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// validateThrottling validates that at least one pod is removed in a batch, that the batch delay is not negative and that the
+// priority order consists of valid selectors.
+func validateThrottling(v *util.Validator, owner string, throttling *wapi.Throttling) {
+	if throttling == nil {
+		return
+	}
+	if throttling.MaxConcurrentRemovals < 1 {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("throttling.maxConcurrentRemovals for %s must be at least 1, found %d", owner, throttling.MaxConcurrentRemovals))
+	}
+	if throttling.BatchDelay != nil && throttling.BatchDelay.Duration < 0 {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("throttling.batchDelay for %s must not be negative, found %s", owner, throttling.BatchDelay.Duration))
+	}
+	for _, selector := range throttling.PriorityOrder {
+		if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+			v.Error = multierr.Append(v.Error, err)
+		}
+	}
+}
+
+// validateEviction validates that the retry interval and the fallback duration are positive.
+func validateEviction(v *util.Validator, eviction *wapi.Eviction) {
+	if eviction == nil {
+		return
+	}
+	if eviction.RetryInterval.Duration <= 0 {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("eviction.retryInterval must be positive, found %s", eviction.RetryInterval.Duration))
+	}
+	if eviction.FallbackToDeleteAfter != nil && eviction.FallbackToDeleteAfter.Duration <= 0 {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("eviction.fallbackToDeleteAfter must be positive, found %s", eviction.FallbackToDeleteAfter.Duration))
+	}
+}
+
+// validateNamespaceSelection validates that the selector is valid and that a namespace is not both included and excluded.
+func validateNamespaceSelection(v *util.Validator, selection *wapi.NamespaceSelection) {
+	if selection == nil {
+		return
+	}
+	if _, err := metav1.LabelSelectorAsSelector(selection.Selector); err != nil {
+		v.Error = multierr.Append(v.Error, err)
+	}
+	for _, namespace := range sets.List(sets.New(selection.Include...).Intersection(sets.New(selection.Exclude...))) {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("namespace %s must not be both included and excluded in namespaceSelection", namespace))
+	}
+}
+
+// validateWeedableConditions validates that the minimum restart count is not negative and that the minimum waiting duration and
+// the not ready duration are positive.
+func validateWeedableConditions(v *util.Validator, owner string, conditions *wapi.WeedableConditions) {
+	if conditions == nil {
+		return
+	}
+	if conditions.MinRestartCount != nil && *conditions.MinRestartCount < 0 {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("weedableConditions.minRestartCount for %s must not be negative, found %d", owner, *conditions.MinRestartCount))
+	}
+	if conditions.MinWaitingDuration != nil && conditions.MinWaitingDuration.Duration <= 0 {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("weedableConditions.minWaitingDuration for %s must be positive, found %s", owner, conditions.MinWaitingDuration.Duration))
+	}
+	if conditions.NotReadyFor != nil && conditions.NotReadyFor.Duration <= 0 {
+		v.Error = multierr.Append(v.Error, fmt.Errorf("weedableConditions.notReadyFor for %s must be positive, found %s", owner, conditions.NotReadyFor.Duration))
+	}
 }
 
 func fillDefaultValues(c *wapi.Config) {
@@ -58,4 +222,36 @@ func fillDefaultValues(c *wapi.Config) {
 			Duration: defaultWatchDuration,
 		}
 	}
+	if c.Eviction != nil && c.Eviction.RetryInterval == nil {
+		c.Eviction.RetryInterval = &metav1.Duration{
+			Duration: defaultEvictionRetryInterval,
+		}
+	}
+	for svcName, ds := range c.ServicesAndDependantSelectors {
+		fillDependantSelectorsDefaultValues(&ds)
+		c.ServicesAndDependantSelectors[svcName] = ds
+	}
+	for i := range c.Dependants {
+		fillDependantSelectorsDefaultValues(&c.Dependants[i].DependantSelectors)
+	}
+}
+
+func fillDependantSelectorsDefaultValues(ds *wapi.DependantSelectors) {
+	if ds.WeedableConditions == nil {
+		ds.WeedableConditions = &wapi.WeedableConditions{}
+	}
+	if len(ds.WeedableConditions.WaitingReasons) == 0 {
+		ds.WeedableConditions.WaitingReasons = []string{crashLoopBackOff}
+	}
+	ds.Action = util.GetValOrDefault(ds.Action, wapi.WeederActionDeletePod)
+}
+
+// GetWatchedServices returns the names of all services whose readiness is relevant to the weeder configuration, i.e. the
+// configured services and the services required by the dependants.
+func GetWatchedServices(c *wapi.Config) sets.Set[string] {
+	services := sets.KeySet(c.ServicesAndDependantSelectors)
+	for _, dependant := range c.Dependants {
+		services.Insert(dependant.RequiredServices...)
+	}
+	return services
 }
@@ -9,7 +9,9 @@ package weeder
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
 	testutil "github.com/gardener/dependency-watchdog/internal/test"
 	multierr "github.com/hashicorp/go-multierror"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -38,6 +40,12 @@ func TestCheckIfDefaultValuesAreSetForAllOptionalMissingValues(t *testing.T) {
 	g.Expect(err).ToNot(HaveOccurred(), "LoadConfig should not give any error for a valid config file")
 	g.Expect(config).ToNot(BeNil(), "LoadConfig should not return nil for a valid config file")
 	g.Expect(*config.WatchDuration).To(Equal(metav1.Duration{Duration: defaultWatchDuration}), "LoadConfig should set watchDuration to defaultWatchDuration if not set in the config file")
+	g.Expect(config.Eviction).To(BeNil(), "LoadConfig should not enable eviction if not set in the config file")
+	for _, ds := range config.ServicesAndDependantSelectors {
+		g.Expect(ds.WeedableConditions).ToNot(BeNil(), "LoadConfig should set weedableConditions if not set in the config file")
+		g.Expect(ds.WeedableConditions.WaitingReasons).To(ConsistOf(crashLoopBackOff), "LoadConfig should set waitingReasons to CrashLoopBackOff if not set in the config file")
+		g.Expect(*ds.Action).To(Equal(wapi.WeederActionDeletePod), "LoadConfig should set action to DeletePod if not set in the config file")
+	}
 	t.Log("All default values are set")
 }
 
@@ -48,6 +56,13 @@ func TestMissingMandatoryFieldsShouldReturnErrorAndNilConfig(t *testing.T) {
 	}{
 		{"config_missing_mandatory_values.yaml", 1},
 		{"config_missing_pod_selectors.yaml", 1},
+		{"config_invalid_weedable_conditions.yaml", 4},
+		{"config_invalid_eviction.yaml", 2},
+		{"config_invalid_throttling.yaml", 3},
+		{"config_invalid_action.yaml", 1},
+		{"config_cyclic_dependant_services.yaml", 2},
+		{"config_invalid_dependants.yaml", 4},
+		{"config_invalid_namespace_selection.yaml", 2},
 	}
 
 	for _, entry := range table {
@@ -79,3 +94,82 @@ func TestValidConfigShouldPassAllValidations(t *testing.T) {
 
 	t.Log("Valid config is loaded correctly")
 }
+
+func TestValidConfigWithWeedableConditionsShouldBeLoaded(t *testing.T) {
+	g := NewWithT(t)
+	configPath := filepath.Join(testdataPath, "valid_config_with_weedable_conditions.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath)
+	g.Expect(err).ToNot(HaveOccurred(), "LoadConfig should not give error for a valid config")
+
+	conditions := config.ServicesAndDependantSelectors["etcd-main-client"].WeedableConditions
+	g.Expect(conditions.IncludeInitContainers).To(BeTrue())
+	g.Expect(conditions.WaitingReasons).To(ConsistOf(crashLoopBackOff, "CreateContainerError"))
+	g.Expect(*conditions.MinRestartCount).To(Equal(int32(2)))
+	g.Expect(conditions.MinWaitingDuration.Duration).To(Equal(time.Minute))
+	g.Expect(conditions.NotReadyFor.Duration).To(Equal(3 * time.Minute))
+	g.Expect(*config.ServicesAndDependantSelectors["etcd-main-client"].Action).To(Equal(wapi.WeederActionRolloutRestart))
+	g.Expect(config.ServicesAndDependantSelectors["etcd-main-client"].WatchDuration.Duration).To(Equal(10 * time.Minute))
+
+	defaultConditions := config.ServicesAndDependantSelectors["kube-apiserver"].WeedableConditions
+	g.Expect(defaultConditions.IncludeInitContainers).To(BeFalse())
+	g.Expect(defaultConditions.WaitingReasons).To(ConsistOf(crashLoopBackOff))
+	g.Expect(defaultConditions.MinRestartCount).To(BeNil())
+	g.Expect(defaultConditions.NotReadyFor).To(BeNil())
+	g.Expect(defaultConditions.MinWaitingDuration).To(BeNil())
+	g.Expect(config.ServicesAndDependantSelectors["kube-apiserver"].WatchDuration).To(BeNil())
+}
+
+func TestValidConfigWithEvictionShouldBeLoaded(t *testing.T) {
+	g := NewWithT(t)
+	configPath := filepath.Join(testdataPath, "valid_config_with_eviction.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath)
+	g.Expect(err).ToNot(HaveOccurred(), "LoadConfig should not give error for a valid config")
+	g.Expect(config.Eviction).ToNot(BeNil())
+	g.Expect(config.Eviction.RetryInterval.Duration).To(Equal(defaultEvictionRetryInterval), "LoadConfig should set retryInterval to defaultEvictionRetryInterval if not set in the config file")
+	g.Expect(config.Eviction.FallbackToDeleteAfter.Duration).To(Equal(2 * time.Minute))
+}
+
+func TestValidConfigWithDependantsShouldBeLoaded(t *testing.T) {
+	g := NewWithT(t)
+	configPath := filepath.Join(testdataPath, "valid_config_with_dependants.yaml")
+	testutil.ValidateIfFileExists(configPath, t)
+	config, err := LoadConfig(configPath)
+	g.Expect(err).ToNot(HaveOccurred(), "LoadConfig should not give error for a config which only configures dependants")
+	g.Expect(config.ServicesAndDependantSelectors).To(BeEmpty())
+	g.Expect(config.Dependants).To(HaveLen(1))
+
+	dependant := config.Dependants[0]
+	g.Expect(dependant.Name).To(Equal("kube-apiserver"))
+	g.Expect(dependant.RequiredServices).To(Equal([]string{"etcd-main-client", "etcd-events-client"}))
+	g.Expect(dependant.PodSelectors).To(HaveLen(1))
+	g.Expect(dependant.WeedableConditions.WaitingReasons).To(ConsistOf(crashLoopBackOff), "LoadConfig should set waitingReasons to CrashLoopBackOff if not set for a dependant")
+	g.Expect(*dependant.Action).To(Equal(wapi.WeederActionRolloutRestart))
+	g.Expect(GetWatchedServices(config).UnsortedList()).To(ConsistOf("etcd-main-client", "etcd-events-client"))
+}
+
+func TestFindDependencyCycle(t *testing.T) {
+	table := []struct {
+		description   string
+		dependants    map[string][]string
+		expectedCycle []string
+	}{
+		{"no dependant services", map[string][]string{"a": nil, "b": nil}, nil},
+		{"chain", map[string][]string{"a": {"b"}, "b": {"c"}, "c": nil}, nil},
+		{"diamond", map[string][]string{"a": {"b", "c"}, "b": {"d"}, "c": {"d"}, "d": nil}, nil},
+		{"self dependency", map[string][]string{"a": {"a"}}, []string{"a", "a"}},
+		{"cycle", map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"b"}}, []string{"b", "c", "b"}},
+	}
+
+	for _, entry := range table {
+		t.Run(entry.description, func(t *testing.T) {
+			g := NewWithT(t)
+			servicesAndDependantSelectors := make(map[string]wapi.DependantSelectors, len(entry.dependants))
+			for svcName, dependantServices := range entry.dependants {
+				servicesAndDependantSelectors[svcName] = wapi.DependantSelectors{DependantServices: dependantServices}
+			}
+			g.Expect(findDependencyCycle(servicesAndDependantSelectors)).To(Equal(entry.expectedCycle))
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package weeder
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// podRemover removes weedable pods. Pods are either deleted or, if eviction is configured, evicted through the Eviction API
+// so that PodDisruptionBudgets are honoured.
+type podRemover struct {
+	eviction *wapi.Eviction
+	mu       sync.Mutex
+	// evictionRejectedSince records for each pod the time at which its eviction has first been rejected.
+	evictionRejectedSince map[types.UID]time.Time
+}
+
+func newPodRemover(eviction *wapi.Eviction) *podRemover {
+	return &podRemover{
+		eviction:              eviction,
+		evictionRejectedSince: make(map[types.UID]time.Time),
+	}
+}
+
+// remove removes the pod and returns whether it has been removed. A pod which does not exist anymore is not removed. If the
+// eviction of the pod has been rejected because it would violate a PodDisruptionBudget, then it returns the duration after
+// which the eviction should be retried.
+func (r *podRemover) remove(ctx context.Context, log logr.Logger, crClient client.Client, pod *v1.Pod, now time.Time) (bool, time.Duration, error) {
+	if r.eviction == nil {
+		log.Info("Deleting pod", "namespace", pod.Namespace, "podName", pod.Name)
+		return deletePod(ctx, crClient, pod)
+	}
+	log.Info("Evicting pod", "namespace", pod.Namespace, "podName", pod.Name)
+	err := crClient.SubResource("eviction").Create(ctx, pod, &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+	})
+	if err == nil || apierrors.IsNotFound(err) {
+		r.forget(pod.UID)
+		return err == nil, 0, nil
+	}
+	if !apierrors.IsTooManyRequests(err) {
+		return false, 0, err
+	}
+	rejectedSince := r.recordRejection(pod.UID, now)
+	if r.eviction.FallbackToDeleteAfter != nil && now.Sub(rejectedSince) >= r.eviction.FallbackToDeleteAfter.Duration {
+		log.Info("Eviction of pod has been rejected for too long, deleting pod", "namespace", pod.Namespace, "podName", pod.Name, "rejectedSince", rejectedSince)
+		r.forget(pod.UID)
+		return deletePod(ctx, crClient, pod)
+	}
+	log.Info("Eviction of pod has been rejected as it would violate a PodDisruptionBudget, will retry", "namespace", pod.Namespace, "podName", pod.Name, "retryInterval", r.eviction.RetryInterval.Duration)
+	return false, r.eviction.RetryInterval.Duration, nil
+}
+
+func deletePod(ctx context.Context, crClient client.Client, pod *v1.Pod) (bool, time.Duration, error) {
+	if err := crClient.Delete(ctx, pod); err != nil {
+		return false, 0, err
+	}
+	return true, 0, nil
+}
+
+// recordRejection records the rejection of the eviction of a pod and returns the time at which it has first been rejected.
+func (r *podRemover) recordRejection(uid types.UID, now time.Time) time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rejectedSince, ok := r.evictionRejectedSince[uid]
+	if !ok {
+		rejectedSince = now
+		r.evictionRejectedSince[uid] = now
+	}
+	return rejectedSince
+}
+
+func (r *podRemover) forget(uid types.UID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.evictionRejectedSince, uid)
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	mockclient "github.com/gardener/dependency-watchdog/internal/mock/controller-runtime/client"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	testRetryInterval  = 10 * time.Second
+	errTooManyRequests = apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+)
+
+func TestPodRemoverShouldDeletePodIfEvictionIsNotConfigured(t *testing.T) {
+	g := NewWithT(t)
+	pod := newTestPod("test-pod", namespace, nil)
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().Delete(gomock.Any(), pod).Return(nil).Times(1)
+
+	removed, retryAfter, err := newPodRemover(nil).remove(context.Background(), logr.Discard(), mockClient, pod, time.Now())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(removed).To(BeTrue())
+	g.Expect(retryAfter).To(BeZero())
+}
+
+func TestPodRemoverShouldEvictPodIfEvictionIsConfigured(t *testing.T) {
+	g := NewWithT(t)
+	pod := newTestPod("test-pod", namespace, nil)
+	ctrl := gomock.NewController(t)
+	mockClient := mockclient.NewMockClient(ctrl)
+	mockSubResourceClient := mockclient.NewMockSubResourceClient(ctrl)
+	mockClient.EXPECT().SubResource("eviction").Return(mockSubResourceClient).Times(1)
+	mockSubResourceClient.EXPECT().Create(gomock.Any(), pod, gomock.Any()).DoAndReturn(func(_ context.Context, _ client.Object, subResource client.Object, _ ...client.SubResourceCreateOption) error {
+		eviction := subResource.(*policyv1.Eviction)
+		g.Expect(eviction.Name).To(Equal(pod.Name))
+		g.Expect(eviction.Namespace).To(Equal(pod.Namespace))
+		return nil
+	}).Times(1)
+
+	remover := newPodRemover(&wapi.Eviction{RetryInterval: &metav1.Duration{Duration: testRetryInterval}})
+	removed, retryAfter, err := remover.remove(context.Background(), logr.Discard(), mockClient, pod, time.Now())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(removed).To(BeTrue())
+	g.Expect(retryAfter).To(BeZero())
+}
+
+func TestPodRemoverShouldNotReportRemovalIfPodIsAlreadyGone(t *testing.T) {
+	g := NewWithT(t)
+	pod := newTestPod("test-pod", namespace, nil)
+	ctrl := gomock.NewController(t)
+	mockClient := mockclient.NewMockClient(ctrl)
+	mockSubResourceClient := mockclient.NewMockSubResourceClient(ctrl)
+	mockClient.EXPECT().SubResource("eviction").Return(mockSubResourceClient).Times(1)
+	mockSubResourceClient.EXPECT().Create(gomock.Any(), pod, gomock.Any()).Return(apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, pod.Name)).Times(1)
+
+	remover := newPodRemover(&wapi.Eviction{RetryInterval: &metav1.Duration{Duration: testRetryInterval}})
+	removed, retryAfter, err := remover.remove(context.Background(), logr.Discard(), mockClient, pod, time.Now())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(removed).To(BeFalse())
+	g.Expect(retryAfter).To(BeZero())
+}
+
+func TestPodRemoverShouldRetryRejectedEviction(t *testing.T) {
+	table := []struct {
+		description           string
+		fallbackToDeleteAfter *metav1.Duration
+		expectDelete          bool
+	}{
+		{"without fallback to delete", nil, false},
+		{"with fallback to delete", &metav1.Duration{Duration: time.Minute}, true},
+	}
+
+	for _, entry := range table {
+		t.Run(entry.description, func(t *testing.T) {
+			g := NewWithT(t)
+			pod := newTestPod("test-pod", namespace, nil)
+			pod.UID = "test-uid"
+			ctrl := gomock.NewController(t)
+			mockClient := mockclient.NewMockClient(ctrl)
+			mockSubResourceClient := mockclient.NewMockSubResourceClient(ctrl)
+			mockClient.EXPECT().SubResource("eviction").Return(mockSubResourceClient).Times(2)
+			mockSubResourceClient.EXPECT().Create(gomock.Any(), pod, gomock.Any()).Return(errTooManyRequests).Times(2)
+			if entry.expectDelete {
+				mockClient.EXPECT().Delete(gomock.Any(), pod).Return(nil).Times(1)
+			}
+
+			remover := newPodRemover(&wapi.Eviction{RetryInterval: &metav1.Duration{Duration: testRetryInterval}, FallbackToDeleteAfter: entry.fallbackToDeleteAfter})
+			now := time.Now()
+			removed, retryAfter, err := remover.remove(context.Background(), logr.Discard(), mockClient, pod, now)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(removed).To(BeFalse())
+			g.Expect(retryAfter).To(Equal(testRetryInterval))
+
+			removed, retryAfter, err = remover.remove(context.Background(), logr.Discard(), mockClient, pod, now.Add(2*time.Minute))
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(removed).To(Equal(entry.expectDelete))
+			if entry.expectDelete {
+				g.Expect(retryAfter).To(BeZero())
+				g.Expect(remover.evictionRejectedSince).To(BeEmpty())
+			} else {
+				g.Expect(retryAfter).To(Equal(testRetryInterval))
+				g.Expect(remover.evictionRejectedSince).To(HaveKeyWithValue(pod.UID, now))
+			}
+		})
+	}
+}
+
+func TestPodRemoverShouldReturnEvictionError(t *testing.T) {
+	g := NewWithT(t)
+	pod := newTestPod("test-pod", namespace, nil)
+	ctrl := gomock.NewController(t)
+	mockClient := mockclient.NewMockClient(ctrl)
+	mockSubResourceClient := mockclient.NewMockSubResourceClient(ctrl)
+	mockClient.EXPECT().SubResource("eviction").Return(mockSubResourceClient).Times(1)
+	mockSubResourceClient.EXPECT().Create(gomock.Any(), pod, gomock.Any()).Return(errors.New("test error")).Times(1)
+
+	remover := newPodRemover(&wapi.Eviction{RetryInterval: &metav1.Duration{Duration: testRetryInterval}})
+	_, _, err := remover.remove(context.Background(), logr.Discard(), mockClient, pod, time.Now())
+	g.Expect(err).To(HaveOccurred())
+}
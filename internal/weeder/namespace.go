@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package weeder
+
+import (
+	"context"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NamespaceMatcher checks if a namespace is selected by the NamespaceSelection of the weeder configuration.
+type NamespaceMatcher struct {
+	include  sets.Set[string]
+	exclude  sets.Set[string]
+	selector labels.Selector
+}
+
+// NewNamespaceMatcher creates a NamespaceMatcher for the given NamespaceSelection. If the selection is nil, then all namespaces are selected.
+func NewNamespaceMatcher(selection *wapi.NamespaceSelection) NamespaceMatcher {
+	m := NamespaceMatcher{}
+	if selection == nil {
+		return m
+	}
+	if len(selection.Include) > 0 {
+		m.include = sets.New(selection.Include...)
+	}
+	m.exclude = sets.New(selection.Exclude...)
+	if selection.Selector != nil {
+		// the selector has already been validated when the configuration has been loaded.
+		if selector, err := metav1.LabelSelectorAsSelector(selection.Selector); err == nil {
+			m.selector = selector
+		}
+	}
+	return m
+}
+
+// MatchesName checks if the namespace is selected by its name, i.e. it is included and is not excluded.
+func (m NamespaceMatcher) MatchesName(namespace string) bool {
+	if m.include != nil && !m.include.Has(namespace) {
+		return false
+	}
+	return !m.exclude.Has(namespace)
+}
+
+// Matches checks if the namespace is selected by its name and its labels. The labels are only read if a selector is configured.
+func (m NamespaceMatcher) Matches(ctx context.Context, reader client.Reader, namespace string) (bool, error) {
+	if !m.MatchesName(namespace) {
+		return false, nil
+	}
+	if m.selector == nil {
+		return true, nil
+	}
+	ns := &v1.Namespace{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return m.selector.Matches(labels.Set(ns.Labels)), nil
+}
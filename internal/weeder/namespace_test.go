@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"context"
+	"testing"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	mockclient "github.com/gardener/dependency-watchdog/internal/mock/controller-runtime/client"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestNamespaceMatcherShouldMatchNamespaceNames(t *testing.T) {
+	table := []struct {
+		description string
+		selection   *wapi.NamespaceSelection
+		namespace   string
+		expected    bool
+	}{
+		{"no selection", nil, "any", true},
+		{"included", &wapi.NamespaceSelection{Include: []string{"shoot--foo"}}, "shoot--foo", true},
+		{"not included", &wapi.NamespaceSelection{Include: []string{"shoot--foo"}}, "shoot--bar", false},
+		{"excluded", &wapi.NamespaceSelection{Exclude: []string{"garden"}}, "garden", false},
+		{"not excluded", &wapi.NamespaceSelection{Exclude: []string{"garden"}}, "shoot--foo", true},
+	}
+
+	for _, entry := range table {
+		t.Run(entry.description, func(t *testing.T) {
+			g := NewWithT(t)
+			// the mock client fails the test on any call, as the labels of a namespace are only read if a selector is configured
+			mockClient := mockclient.NewMockClient(gomock.NewController(t))
+			matches, err := NewNamespaceMatcher(entry.selection).Matches(context.Background(), mockClient, entry.namespace)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(matches).To(Equal(entry.expected))
+		})
+	}
+}
+
+func TestNamespaceMatcherShouldMatchNamespaceLabels(t *testing.T) {
+	g := NewWithT(t)
+	namespaceLabels := map[string]map[string]string{
+		"shoot--foo": {"gardener.cloud/role": "shoot"},
+		"tenant":     {"team": "other"},
+	}
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+		nsLabels, ok := namespaceLabels[key.Name]
+		if !ok {
+			return apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, key.Name)
+		}
+		obj.(*v1.Namespace).Labels = nsLabels
+		return nil
+	}).Times(3)
+
+	matcher := NewNamespaceMatcher(&wapi.NamespaceSelection{
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"gardener.cloud/role": "shoot"}},
+		Exclude:  []string{"garden"},
+	})
+	for namespace, expected := range map[string]bool{"shoot--foo": true, "tenant": false, "deleted": false, "garden": false} {
+		matches, err := matcher.Matches(context.Background(), mockClient, namespace)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(matches).To(Equal(expected), "unexpected match for namespace %s", namespace)
+	}
+}
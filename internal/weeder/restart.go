@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package weeder
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// restartedAtAnnotationKey is the pod template annotation which is also updated by `kubectl rollout restart`.
+const restartedAtAnnotationKey = "kubectl.kubernetes.io/restartedAt"
+
+// workloadRestarter triggers a rollout restart of the workloads owning weedable pods. Each workload is restarted at most once
+// during the lifetime of the workloadRestarter, which is the lifetime of its weeder.
+type workloadRestarter struct {
+	mu sync.Mutex
+	// restarted are the workloads which have already been restarted, identified by their kind, namespace and name.
+	restarted sets.Set[string]
+}
+
+func newWorkloadRestarter() *workloadRestarter {
+	return &workloadRestarter{restarted: sets.New[string]()}
+}
+
+// restart triggers a rollout restart of the Deployment, StatefulSet or DaemonSet owning the pod, unless it has already been
+// restarted. It only reports the pod as removed if it has triggered the rollout restart. A pod which is not owned by any of
+// these workloads is left untouched, and a pod whose workload has already been restarted is left to the running rollout.
+func (r *workloadRestarter) restart(ctx context.Context, log logr.Logger, crClient client.Client, pod *v1.Pod, now time.Time) (bool, time.Duration, error) {
+	workload, kind, err := getOwningWorkload(ctx, crClient, pod)
+	if err != nil {
+		return false, 0, err
+	}
+	if workload == nil {
+		log.Info("Pod is not owned by a Deployment, StatefulSet or DaemonSet, skipping rollout restart", "namespace", pod.Namespace, "podName", pod.Name)
+		return false, 0, nil
+	}
+	workloadKey := kind + "/" + client.ObjectKeyFromObject(workload).String()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.restarted.Has(workloadKey) {
+		return false, 0, nil
+	}
+	log.Info("Triggering rollout restart of workload owning pod", "namespace", pod.Namespace, "podName", pod.Name, "workload", workloadKey)
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`, restartedAtAnnotationKey, now.Format(time.RFC3339))
+	if err = crClient.Patch(ctx, workload, client.RawPatch(types.MergePatchType, []byte(patch))); err != nil {
+		return false, 0, err
+	}
+	r.restarted.Insert(workloadKey)
+	return true, 0, nil
+}
+
+// getOwningWorkload returns the Deployment, StatefulSet or DaemonSet which controls the pod together with its kind. It returns
+// nil if the pod is not controlled by any of these workloads.
+func getOwningWorkload(ctx context.Context, crClient client.Client, pod *v1.Pod) (client.Object, string, error) {
+	ownerRef := metav1.GetControllerOf(pod)
+	if ownerRef == nil || ownerRef.APIVersion != appsv1.SchemeGroupVersion.String() {
+		return nil, "", nil
+	}
+	objectMeta := metav1.ObjectMeta{Namespace: pod.Namespace, Name: ownerRef.Name}
+	switch ownerRef.Kind {
+	case "StatefulSet":
+		return &appsv1.StatefulSet{ObjectMeta: objectMeta}, ownerRef.Kind, nil
+	case "DaemonSet":
+		return &appsv1.DaemonSet{ObjectMeta: objectMeta}, ownerRef.Kind, nil
+	case "ReplicaSet":
+		// only the metadata of the ReplicaSet is required to find the owning Deployment.
+		rs := &metav1.PartialObjectMetadata{}
+		rs.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind(ownerRef.Kind))
+		if err := crClient.Get(ctx, client.ObjectKey{Namespace: pod.Namespace, Name: ownerRef.Name}, rs); err != nil {
+			return nil, "", err
+		}
+		rsOwnerRef := metav1.GetControllerOf(rs)
+		if rsOwnerRef == nil || rsOwnerRef.APIVersion != appsv1.SchemeGroupVersion.String() || rsOwnerRef.Kind != "Deployment" {
+			return nil, "", nil
+		}
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: rsOwnerRef.Name}}, rsOwnerRef.Kind, nil
+	default:
+		return nil, "", nil
+	}
+}
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockclient "github.com/gardener/dependency-watchdog/internal/mock/controller-runtime/client"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestWorkloadRestarterShouldRestartOwningDeploymentOnce(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{Namespace: namespace, Name: "kube-apiserver-abc"}, gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+		rs := obj.(*metav1.PartialObjectMetadata)
+		g.Expect(rs.GroupVersionKind()).To(Equal(appsv1.SchemeGroupVersion.WithKind("ReplicaSet")))
+		rs.OwnerReferences = []metav1.OwnerReference{createControllerRef("Deployment", "kube-apiserver")}
+		return nil
+	}).Times(2)
+	mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, obj client.Object, patch client.Patch, _ ...client.PatchOption) error {
+		g.Expect(obj).To(BeAssignableToTypeOf(&appsv1.Deployment{}))
+		g.Expect(client.ObjectKeyFromObject(obj)).To(Equal(client.ObjectKey{Namespace: namespace, Name: "kube-apiserver"}))
+		g.Expect(patch.Type()).To(Equal(types.MergePatchType))
+		data, err := patch.Data(obj)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(data)).To(ContainSubstring(restartedAtAnnotationKey))
+		g.Expect(string(data)).To(ContainSubstring(now.Format(time.RFC3339)))
+		return nil
+	}).Times(1)
+
+	r := newWorkloadRestarter()
+	for i, podName := range []string{"kube-apiserver-abc-1", "kube-apiserver-abc-2"} {
+		pod := newTestPod(podName, namespace, nil)
+		pod.OwnerReferences = []metav1.OwnerReference{createControllerRef("ReplicaSet", "kube-apiserver-abc")}
+		removed, retryAfter, err := r.restart(context.Background(), logr.Discard(), mockClient, pod, now)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(removed).To(Equal(i == 0), "only the pod which has triggered the rollout restart should be reported as removed")
+		g.Expect(retryAfter).To(BeZero())
+	}
+	g.Expect(r.restarted.UnsortedList()).To(ConsistOf("Deployment/" + namespace + "/kube-apiserver"))
+}
+
+func TestWorkloadRestarterShouldRestartOwningStatefulSet(t *testing.T) {
+	g := NewWithT(t)
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+		g.Expect(obj).To(BeAssignableToTypeOf(&appsv1.StatefulSet{}))
+		g.Expect(obj.GetName()).To(Equal("etcd-main"))
+		return nil
+	}).Times(1)
+
+	pod := newTestPod("etcd-main-0", namespace, nil)
+	pod.OwnerReferences = []metav1.OwnerReference{createControllerRef("StatefulSet", "etcd-main")}
+	_, _, err := newWorkloadRestarter().restart(context.Background(), logr.Discard(), mockClient, pod, time.Now())
+	g.Expect(err).ToNot(HaveOccurred())
+}
+
+func TestWorkloadRestarterShouldSkipPodsWithoutOwningWorkload(t *testing.T) {
+	g := NewWithT(t)
+	// the mock client fails the test on any call
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	r := newWorkloadRestarter()
+
+	unownedPod := newTestPod("unowned", namespace, nil)
+	jobPod := newTestPod("job-pod", namespace, nil)
+	jobPod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "Job", Name: "job", Controller: pointer.Bool(true)}}
+	for _, pod := range []*v1.Pod{unownedPod, jobPod} {
+		removed, _, err := r.restart(context.Background(), logr.Discard(), mockClient, pod, time.Now())
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(removed).To(BeFalse())
+	}
+	g.Expect(r.restarted).To(BeEmpty())
+}
+
+func createControllerRef(kind, name string) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: appsv1.SchemeGroupVersion.String(),
+		Kind:       kind,
+		Name:       name,
+		Controller: pointer.Bool(true),
+	}
+}
@@ -0,0 +1,243 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package weeder
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/gardener/dependency-watchdog/internal/util"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// podGoneCheckInterval is the interval at which it is checked if the pods of a batch are gone.
+const podGoneCheckInterval = 2 * time.Second
+
+// removalQueue queues the weedable pods of a service, so that they can be removed in batches in the configured priority order.
+type removalQueue struct {
+	maxConcurrentRemovals int
+	batchDelay            time.Duration
+	priorityOrder         []labels.Selector
+	mu                    sync.Mutex
+	pods                  map[types.NamespacedName]queuedPod
+	// added is signalled when a pod has been added to the queue.
+	added chan struct{}
+}
+
+type queuedPod struct {
+	key               types.NamespacedName
+	labels            map[string]string
+	creationTimestamp time.Time
+	// notBefore is the time before which the pod is not removed, e.g. because its eviction has been rejected.
+	notBefore time.Time
+}
+
+func newRemovalQueue(throttling *wapi.Throttling) *removalQueue {
+	priorityOrder := make([]labels.Selector, 0, len(throttling.PriorityOrder))
+	for _, ls := range throttling.PriorityOrder {
+		// the selectors have already been validated when the configuration has been loaded.
+		if selector, err := metav1.LabelSelectorAsSelector(ls); err == nil {
+			priorityOrder = append(priorityOrder, selector)
+		}
+	}
+	var batchDelay time.Duration
+	if throttling.BatchDelay != nil {
+		batchDelay = throttling.BatchDelay.Duration
+	}
+	return &removalQueue{
+		maxConcurrentRemovals: throttling.MaxConcurrentRemovals,
+		batchDelay:            batchDelay,
+		priorityOrder:         priorityOrder,
+		pods:                  make(map[types.NamespacedName]queuedPod),
+		added:                 make(chan struct{}, 1),
+	}
+}
+
+// add queues the pod for removal not before the given time. If the pod is already queued, then only its labels are updated.
+func (q *removalQueue) add(pod *v1.Pod, notBefore time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	key := client.ObjectKeyFromObject(pod)
+	if existing, ok := q.pods[key]; ok {
+		existing.labels = pod.Labels
+		q.pods[key] = existing
+		return
+	}
+	q.pods[key] = queuedPod{
+		key:               key,
+		labels:            pod.Labels,
+		creationTimestamp: pod.CreationTimestamp.Time,
+		notBefore:         notBefore,
+	}
+	select {
+	case q.added <- struct{}{}:
+	default:
+	}
+}
+
+// nextDue returns the earliest time at which a queued pod can be removed. It returns false if the queue is empty.
+func (q *removalQueue) nextDue() (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var nextDue time.Time
+	for _, qp := range q.pods {
+		if nextDue.IsZero() || qp.notBefore.Before(nextDue) {
+			nextDue = qp.notBefore
+		}
+	}
+	return nextDue, len(q.pods) > 0
+}
+
+// popBatch removes the next batch of pods from the queue and returns it. A batch consists of at most maxConcurrentRemovals
+// pods which are due for removal, ordered by their priority, then by their age with the oldest pod first.
+func (q *removalQueue) popBatch(now time.Time) []queuedPod {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	due := make([]queuedPod, 0, len(q.pods))
+	for _, qp := range q.pods {
+		if !qp.notBefore.After(now) {
+			due = append(due, qp)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if pi, pj := q.priority(due[i].labels), q.priority(due[j].labels); pi != pj {
+			return pi < pj
+		}
+		if !due[i].creationTimestamp.Equal(due[j].creationTimestamp) {
+			return due[i].creationTimestamp.Before(due[j].creationTimestamp)
+		}
+		return due[i].key.String() < due[j].key.String()
+	})
+	if len(due) > q.maxConcurrentRemovals {
+		due = due[:q.maxConcurrentRemovals]
+	}
+	for _, qp := range due {
+		delete(q.pods, qp.key)
+	}
+	return due
+}
+
+// priority returns the index of the first selector in the priority order which matches the labels. Pods which do not match
+// any selector have the lowest priority.
+func (q *removalQueue) priority(podLabels map[string]string) int {
+	for i, selector := range q.priorityOrder {
+		if selector.Matches(labels.Set(podLabels)) {
+			return i
+		}
+	}
+	return len(q.priorityOrder)
+}
+
+// removeQueuedPods removes the queued weedable pods in batches until the context of the weeder has timed-out or has been
+// cancelled. The next batch is only removed once the pods of the previous batch are gone and the batch delay has passed.
+func (w *Weeder) removeQueuedPods() {
+	for {
+		if !w.waitForDuePods() {
+			return
+		}
+		removed := w.removeBatch(time.Now())
+		if len(removed) == 0 {
+			continue
+		}
+		if !w.waitUntilPodsAreGone(removed) {
+			return
+		}
+		if err := util.SleepWithContext(w.ctx, w.removalQueue.batchDelay); err != nil {
+			return
+		}
+	}
+}
+
+// waitForDuePods waits until a queued pod is due for removal. It returns false if the context of the weeder is done.
+func (w *Weeder) waitForDuePods() bool {
+	for {
+		nextDue, ok := w.removalQueue.nextDue()
+		if ok && !nextDue.After(time.Now()) {
+			return true
+		}
+		var timer *time.Timer
+		var dueC <-chan time.Time
+		if ok {
+			timer = time.NewTimer(time.Until(nextDue))
+			dueC = timer.C
+		}
+		select {
+		case <-w.ctx.Done():
+			stopTimer(timer)
+			return false
+		case <-w.removalQueue.added:
+			stopTimer(timer)
+		case <-dueC:
+		}
+	}
+}
+
+// removeBatch removes the next batch of queued pods which are still weedable and returns the pods which have actually been
+// removed. Pods whose eviction has been rejected are queued again.
+func (w *Weeder) removeBatch(now time.Time) []*v1.Pod {
+	var removedPods []*v1.Pod
+	for _, qp := range w.removalQueue.popBatch(now) {
+		pod := &v1.Pod{}
+		if err := w.ctrlClient.Get(w.ctx, qp.key, pod); err != nil {
+			if !apierrors.IsNotFound(err) {
+				w.logger.Error(err, "Error getting queued pod", "namespace", qp.key.Namespace, "podName", qp.key.Name)
+			}
+			continue
+		}
+		if weedable, _ := shouldDeletePod(pod, w.weedableConditions, now); !weedable {
+			w.logger.Info("Skipping queued pod as it is no longer weedable", "namespace", pod.Namespace, "podName", pod.Name)
+			continue
+		}
+		removed, retryAfter, err := w.removePod(w.ctx, w.logger, w.ctrlClient, pod, now)
+		if err != nil {
+			w.logger.Error(err, "Error removing queued pod", "namespace", pod.Namespace, "podName", pod.Name)
+			continue
+		}
+		if retryAfter > 0 {
+			w.removalQueue.add(pod, now.Add(retryAfter))
+			continue
+		}
+		if removed {
+			removedPods = append(removedPods, pod)
+		}
+	}
+	return removedPods
+}
+
+// waitUntilPodsAreGone waits until all pods are gone, i.e. they either do not exist anymore or have been replaced by a pod with
+// the same name. It returns false if the context of the weeder is done.
+func (w *Weeder) waitUntilPodsAreGone(pods []*v1.Pod) bool {
+	err := wait.PollUntilContextCancel(w.ctx, podGoneCheckInterval, true, func(ctx context.Context) (bool, error) {
+		for _, pod := range pods {
+			current := &v1.Pod{}
+			if err := w.ctrlClient.Get(ctx, client.ObjectKeyFromObject(pod), current); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return false, nil
+			}
+			if current.UID == pod.UID {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	return err == nil
+}
+
+func stopTimer(timer *time.Timer) {
+	if timer != nil {
+		timer.Stop()
+	}
+}
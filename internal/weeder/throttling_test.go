@@ -0,0 +1,223 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	mockclient "github.com/gardener/dependency-watchdog/internal/mock/controller-runtime/client"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var testPriorityOrder = []*metav1.LabelSelector{{MatchLabels: map[string]string{"role": "first"}}, {MatchLabels: map[string]string{"role": "second"}}}
+
+func TestRemovalQueueShouldPopBatchesInPriorityOrder(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+	q := newRemovalQueue(&wapi.Throttling{MaxConcurrentRemovals: 2, PriorityOrder: testPriorityOrder})
+
+	q.add(newQueueTestPod("unprioritised", nil, now.Add(-time.Hour)), now)
+	q.add(newQueueTestPod("second", map[string]string{"role": "second"}, now.Add(-time.Hour)), now)
+	q.add(newQueueTestPod("first-new", map[string]string{"role": "first"}, now.Add(-time.Minute)), now)
+	q.add(newQueueTestPod("first-old", map[string]string{"role": "first"}, now.Add(-time.Hour)), now)
+	q.add(newQueueTestPod("not-due", map[string]string{"role": "first"}, now.Add(-time.Hour)), now.Add(time.Minute))
+
+	g.Expect(queuedPodNames(q.popBatch(now))).To(Equal([]string{"first-old", "first-new"}))
+	g.Expect(queuedPodNames(q.popBatch(now))).To(Equal([]string{"second", "unprioritised"}))
+	g.Expect(q.popBatch(now)).To(BeEmpty())
+
+	nextDue, ok := q.nextDue()
+	g.Expect(ok).To(BeTrue())
+	g.Expect(nextDue).To(Equal(now.Add(time.Minute)))
+	g.Expect(queuedPodNames(q.popBatch(now.Add(time.Minute)))).To(Equal([]string{"not-due"}))
+	_, ok = q.nextDue()
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestRemovalQueueShouldNotDelayAlreadyQueuedPod(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+	q := newRemovalQueue(&wapi.Throttling{MaxConcurrentRemovals: 1, PriorityOrder: testPriorityOrder})
+
+	q.add(newQueueTestPod("pod", nil, now), now)
+	g.Eventually(q.added).Should(Receive())
+	q.add(newQueueTestPod("pod", map[string]string{"role": "first"}, now), now.Add(time.Hour))
+	g.Consistently(q.added).ShouldNot(Receive())
+
+	batch := q.popBatch(now)
+	g.Expect(batch).To(HaveLen(1))
+	g.Expect(batch[0].labels).To(HaveKeyWithValue("role", "first"))
+	g.Expect(batch[0].notBefore).To(Equal(now))
+}
+
+func TestRemoveBatchShouldOnlyRemoveWeedablePods(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+	crashingPod := newQueueTestPod("crashing", nil, now)
+	crashingPod.Status.ContainerStatuses = []v1.ContainerStatus{createWaitingContainerStatus(crashLoopBackOff, 1)}
+	recoveredPod := newQueueTestPod("recovered", nil, now)
+	pods := map[types.NamespacedName]*v1.Pod{
+		client.ObjectKeyFromObject(crashingPod):  crashingPod,
+		client.ObjectKeyFromObject(recoveredPod): recoveredPod,
+	}
+
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+		pod, ok := pods[key]
+		if !ok {
+			return apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, key.Name)
+		}
+		pod.DeepCopyInto(obj.(*v1.Pod))
+		return nil
+	}).Times(3)
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+		g.Expect(obj.GetName()).To(Equal(crashingPod.Name))
+		return nil
+	}).Times(1)
+
+	config := &wapi.Config{
+		WatchDuration: testWeederConfig.WatchDuration,
+		ServicesAndDependantSelectors: map[string]wapi.DependantSelectors{
+			epName: {
+				PodSelectors: testServicesAndDependantSelectors[epName].PodSelectors,
+				Throttling:   &wapi.Throttling{MaxConcurrentRemovals: 5},
+			},
+		},
+	}
+	w := NewWeeder(context.Background(), testService, config, mockClient, nil, logr.Discard())
+	defer w.cancelFn()
+	g.Expect(w.removalQueue).ToNot(BeNil())
+
+	w.removalQueue.add(crashingPod, now)
+	w.removalQueue.add(recoveredPod, now)
+	w.removalQueue.add(newQueueTestPod("gone", nil, now), now)
+	removed := w.removeBatch(now)
+	g.Expect(removed).To(HaveLen(1))
+	g.Expect(removed[0].Name).To(Equal(crashingPod.Name))
+	_, ok := w.removalQueue.nextDue()
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestRemoveBatchShouldOnlyReturnPodsWhichHaveBeenRemoved(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+	removedPod := newQueueTestPod("removed", nil, now)
+	skippedPod := newQueueTestPod("skipped", nil, now)
+	for _, pod := range []*v1.Pod{removedPod, skippedPod} {
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{createWaitingContainerStatus(crashLoopBackOff, 1)}
+	}
+	pods := map[types.NamespacedName]*v1.Pod{
+		client.ObjectKeyFromObject(removedPod): removedPod,
+		client.ObjectKeyFromObject(skippedPod): skippedPod,
+	}
+
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+		pods[key].DeepCopyInto(obj.(*v1.Pod))
+		return nil
+	}).Times(2)
+
+	config := &wapi.Config{
+		WatchDuration: testWeederConfig.WatchDuration,
+		ServicesAndDependantSelectors: map[string]wapi.DependantSelectors{
+			epName: {
+				PodSelectors: testServicesAndDependantSelectors[epName].PodSelectors,
+				Throttling:   &wapi.Throttling{MaxConcurrentRemovals: 5},
+			},
+		},
+	}
+	w := NewWeeder(context.Background(), testService, config, mockClient, nil, logr.Discard())
+	defer w.cancelFn()
+	// the pod removal func leaves the skipped pod untouched, as e.g. a rollout restart does for a pod without owning workload.
+	w.removePod = func(_ context.Context, _ logr.Logger, _ client.Client, pod *v1.Pod, _ time.Time) (bool, time.Duration, error) {
+		return pod.Name == removedPod.Name, 0, nil
+	}
+
+	w.removalQueue.add(removedPod, now)
+	w.removalQueue.add(skippedPod, now)
+	removed := w.removeBatch(now)
+	g.Expect(removed).To(HaveLen(1))
+	g.Expect(removed[0].Name).To(Equal(removedPod.Name))
+	_, ok := w.removalQueue.nextDue()
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestThrottledRolloutRestartShouldOnlyReturnPodWhichTriggeredTheRestart(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+	unownedPod := newQueueTestPod("unowned", nil, now.Add(-time.Hour))
+	firstPod := newQueueTestPod("kube-apiserver-abc-1", nil, now.Add(-time.Minute))
+	secondPod := newQueueTestPod("kube-apiserver-abc-2", nil, now)
+	for _, pod := range []*v1.Pod{firstPod, secondPod} {
+		pod.OwnerReferences = []metav1.OwnerReference{createControllerRef("ReplicaSet", "kube-apiserver-abc")}
+	}
+	pods := map[types.NamespacedName]*v1.Pod{}
+	for _, pod := range []*v1.Pod{unownedPod, firstPod, secondPod} {
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{createWaitingContainerStatus(crashLoopBackOff, 1)}
+		pods[client.ObjectKeyFromObject(pod)] = pod
+	}
+
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+		if rs, ok := obj.(*metav1.PartialObjectMetadata); ok {
+			rs.OwnerReferences = []metav1.OwnerReference{createControllerRef("Deployment", "kube-apiserver")}
+			return nil
+		}
+		pods[key].DeepCopyInto(obj.(*v1.Pod))
+		return nil
+	}).Times(5)
+	mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+		g.Expect(client.ObjectKeyFromObject(obj)).To(Equal(client.ObjectKey{Namespace: namespace, Name: "kube-apiserver"}))
+		return nil
+	}).Times(1)
+
+	rolloutRestart := wapi.WeederActionRolloutRestart
+	config := &wapi.Config{
+		WatchDuration: testWeederConfig.WatchDuration,
+		ServicesAndDependantSelectors: map[string]wapi.DependantSelectors{
+			epName: {
+				PodSelectors: testServicesAndDependantSelectors[epName].PodSelectors,
+				Action:       &rolloutRestart,
+				Throttling:   &wapi.Throttling{MaxConcurrentRemovals: 5},
+			},
+		},
+	}
+	w := NewWeeder(context.Background(), testService, config, mockClient, nil, logr.Discard())
+	defer w.cancelFn()
+
+	for _, pod := range []*v1.Pod{unownedPod, firstPod, secondPod} {
+		w.removalQueue.add(pod, now)
+	}
+	removed := w.removeBatch(now)
+	g.Expect(removed).To(HaveLen(1), "neither the unowned pod nor the pod whose deployment has already been restarted should be waited for")
+	g.Expect(removed[0].Name).To(Equal(firstPod.Name))
+}
+
+func newQueueTestPod(name string, labels map[string]string, creationTimestamp time.Time) *v1.Pod {
+	pod := newTestPod(name, namespace, labels)
+	pod.CreationTimestamp = metav1.NewTime(creationTimestamp)
+	return pod
+}
+
+func queuedPodNames(batch []queuedPod) []string {
+	names := make([]string, 0, len(batch))
+	for _, qp := range batch {
+		names = append(names, qp.key.Name)
+	}
+	return names
+}
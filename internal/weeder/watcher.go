@@ -6,29 +6,33 @@ package weeder
 
 import (
 	"context"
-	"fmt"
+	"sync"
 	"time"
 
-	"github.com/gardener/dependency-watchdog/internal/util"
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/watch"
-	"k8s.io/client-go/kubernetes"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const watchCreationRetryInterval = 500 * time.Millisecond
+// podEventHandler processes a pod event. If the pod has to be processed again after some time even if there is no further
+// event for it, then it returns the duration after which the pod should be processed again.
+type podEventHandler func(ctx context.Context, log logr.Logger, crClient client.Client, targetPod *v1.Pod) (time.Duration, error)
 
-type podEventHandler func(ctx context.Context, log logr.Logger, crClient client.Client, targetPod *v1.Pod) error
-
-// podWatcher watches a pod for status changes
+// podWatcher watches pods matching a selector for status changes. Instead of opening its own watch, it registers an event
+// handler with the shared pod informer, so that the pod events for all weeders are multiplexed from a single watch.
 type podWatcher struct {
 	weeder         *Weeder
 	selector       *metav1.LabelSelector
 	eventHandlerFn podEventHandler
-	k8sWatch       watch.Interface
 	log            logr.Logger
+	mu             sync.Mutex
+	// rechecks are the timers for the pods which will be processed again, as requested by the eventHandlerFn.
+	rechecks map[types.NamespacedName]*time.Timer
 }
 
 func newPodWatcher(weeder *Weeder, selector *metav1.LabelSelector, eventHandlerFn podEventHandler) *podWatcher {
@@ -36,69 +40,104 @@ func newPodWatcher(weeder *Weeder, selector *metav1.LabelSelector, eventHandlerF
 		weeder:         weeder,
 		selector:       selector,
 		eventHandlerFn: eventHandlerFn,
-		k8sWatch:       nil,
 		log:            weeder.logger,
+		rechecks:       make(map[types.NamespacedName]*time.Timer),
 	}
 }
 
-func (pw *podWatcher) close() {
-	if pw.k8sWatch != nil {
-		pw.k8sWatch.Stop()
-	}
-}
-
+// watch registers an event handler for the pods in the namespace of the weeder which match the selector, and removes it once
+// the context of the weeder has timed-out or has been cancelled. Once registered, the handler is also called for all existing
+// matching pods.
 func (pw *podWatcher) watch() {
-	defer pw.close()
-	pw.createK8sWatch(pw.weeder.ctx)
+	selector, err := metav1.LabelSelectorAsSelector(pw.selector)
+	if err != nil {
+		pw.log.Error(err, "Invalid pod selector, pods will not be watched", "namespace", pw.weeder.namespace, "service", pw.weeder.serviceName, "selector", pw.selector.String())
+		return
+	}
+	registration, err := pw.weeder.podInformer.AddEventHandler(toolscache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			return pw.matches(obj, selector)
+		},
+		Handler: toolscache.ResourceEventHandlerFuncs{
+			AddFunc: pw.handle,
+			UpdateFunc: func(_, newObj interface{}) {
+				pw.handle(newObj)
+			},
+		},
+	})
+	if err != nil {
+		pw.log.Error(err, "Failed to register pod event handler, pods will not be watched", "namespace", pw.weeder.namespace, "service", pw.weeder.serviceName, "selector", pw.selector.String())
+		return
+	}
 	pw.log.Info("Watching for pods in CrashLoopBackoff")
-	for {
-		select {
-		case <-pw.weeder.ctx.Done():
-			pw.log.Info("Exiting watch as context has timed-out or has been cancelled", "namespace", pw.weeder.namespace, "endpoint", pw.weeder.endpoints.Name, "selector", pw.selector.String())
-			return
-		case event, ok := <-pw.k8sWatch.ResultChan():
-			if !ok {
-				pw.log.V(3).Info("Watch has stopped, recreating kubernetes watch", "namespace", pw.weeder.namespace, "endpoint", pw.weeder.endpoints.Name, "selector", pw.selector.String())
-				pw.createK8sWatch(pw.weeder.ctx)
-				continue
-			}
-			if !canProcessEvent(event) {
-				continue
-			}
-			targetPod := event.Object.(*v1.Pod)
-			if err := pw.eventHandlerFn(pw.weeder.ctx, pw.log, pw.weeder.ctrlClient, targetPod); err != nil {
-				pw.log.Error(err, "Error processing pod", "namespace", pw.weeder.namespace, "podName", targetPod.Name)
-			}
-		}
+	<-pw.weeder.ctx.Done()
+	pw.log.Info("Exiting watch as context has timed-out or has been cancelled", "namespace", pw.weeder.namespace, "service", pw.weeder.serviceName, "selector", pw.selector.String())
+	if err = pw.weeder.podInformer.RemoveEventHandler(registration); err != nil {
+		pw.log.Error(err, "Failed to remove pod event handler", "namespace", pw.weeder.namespace, "service", pw.weeder.serviceName, "selector", pw.selector.String())
 	}
+	pw.stopRechecks()
 }
 
-func (pw *podWatcher) createK8sWatch(ctx context.Context) {
-	operation := fmt.Sprintf("Creating kubernetes watch for namespace %s, service %s with selector %s", pw.weeder.namespace, pw.weeder.endpoints.Name, pw.selector)
-	util.RetryOnError(ctx, pw.log, operation, func() error {
-		w, err := doCreateK8sWatch(ctx, pw.weeder.watchClient, pw.weeder.namespace, pw.selector)
-		if err != nil {
-			return err
-		}
-		pw.k8sWatch = w
-		return nil
-	}, watchCreationRetryInterval)
+// matches checks if the object is a pod in the namespace of the weeder whose labels match the selector.
+func (pw *podWatcher) matches(obj interface{}, selector labels.Selector) bool {
+	pod, ok := obj.(*v1.Pod)
+	return ok && pod.Namespace == pw.weeder.namespace && selector.Matches(labels.Set(pod.Labels))
+}
+
+func (pw *podWatcher) handle(obj interface{}) {
+	// events which are still queued for the handler when the weeder is done, are dropped.
+	if pw.weeder.ctx.Err() != nil {
+		return
+	}
+	pw.process(obj.(*v1.Pod))
 }
 
-func doCreateK8sWatch(ctx context.Context, client kubernetes.Interface, namespace string, lSelector *metav1.LabelSelector) (watch.Interface, error) {
-	selector, err := metav1.LabelSelectorAsSelector(lSelector)
+func (pw *podWatcher) process(targetPod *v1.Pod) {
+	recheckAfter, err := pw.eventHandlerFn(pw.weeder.ctx, pw.log, pw.weeder.ctrlClient, targetPod)
 	if err != nil {
-		return nil, err
+		pw.log.Error(err, "Error processing pod", "namespace", pw.weeder.namespace, "podName", targetPod.Name)
+		return
+	}
+	if recheckAfter > 0 {
+		pw.scheduleRecheck(client.ObjectKeyFromObject(targetPod), recheckAfter)
+	}
+}
+
+// scheduleRecheck schedules the pod to be processed again after the given duration. A pending recheck for the same pod is replaced.
+func (pw *podWatcher) scheduleRecheck(key types.NamespacedName, after time.Duration) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if timer, ok := pw.rechecks[key]; ok {
+		timer.Stop()
 	}
-	w, err := client.CoreV1().Pods(namespace).Watch(ctx, metav1.ListOptions{
-		LabelSelector: selector.String(),
+	pw.rechecks[key] = time.AfterFunc(after, func() {
+		pw.recheck(key)
 	})
-	if err != nil {
-		return nil, err
+}
+
+// recheck gets the current state of the pod and processes it again, unless the weeder is done by then.
+func (pw *podWatcher) recheck(key types.NamespacedName) {
+	pw.mu.Lock()
+	delete(pw.rechecks, key)
+	pw.mu.Unlock()
+	if pw.weeder.ctx.Err() != nil {
+		return
 	}
-	return w, nil
+	targetPod := &v1.Pod{}
+	if err := pw.weeder.ctrlClient.Get(pw.weeder.ctx, key, targetPod); err != nil {
+		if !apierrors.IsNotFound(err) {
+			pw.log.Error(err, "Error getting pod for recheck", "namespace", key.Namespace, "podName", key.Name)
+		}
+		return
+	}
+	pw.process(targetPod)
 }
 
-func canProcessEvent(ev watch.Event) bool {
-	return ev.Type == watch.Added || ev.Type == watch.Modified
+func (pw *podWatcher) stopRechecks() {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	for key, timer := range pw.rechecks {
+		timer.Stop()
+		delete(pw.rechecks, key)
+	}
 }
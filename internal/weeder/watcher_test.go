@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mockclient "github.com/gardener/dependency-watchdog/internal/mock/controller-runtime/client"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakePodInformer is a minimal informer which records the registered event handlers and allows to emit events to them.
+type fakePodInformer struct {
+	mu       sync.Mutex
+	handlers map[*fakeRegistration]toolscache.ResourceEventHandler
+}
+
+type fakeRegistration struct{}
+
+func (r *fakeRegistration) HasSynced() bool { return true }
+
+func newFakePodInformer() *fakePodInformer {
+	return &fakePodInformer{handlers: make(map[*fakeRegistration]toolscache.ResourceEventHandler)}
+}
+
+func (f *fakePodInformer) AddEventHandler(handler toolscache.ResourceEventHandler) (toolscache.ResourceEventHandlerRegistration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r := &fakeRegistration{}
+	f.handlers[r] = handler
+	return r, nil
+}
+
+func (f *fakePodInformer) AddEventHandlerWithResyncPeriod(handler toolscache.ResourceEventHandler, _ time.Duration) (toolscache.ResourceEventHandlerRegistration, error) {
+	return f.AddEventHandler(handler)
+}
+
+func (f *fakePodInformer) RemoveEventHandler(handle toolscache.ResourceEventHandlerRegistration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.handlers, handle.(*fakeRegistration))
+	return nil
+}
+
+func (f *fakePodInformer) AddIndexers(_ toolscache.Indexers) error { return nil }
+
+func (f *fakePodInformer) HasSynced() bool { return true }
+
+func (f *fakePodInformer) handlerCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.handlers)
+}
+
+func (f *fakePodInformer) emitAdd(pod *v1.Pod) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, h := range f.handlers {
+		h.OnAdd(pod, false)
+	}
+}
+
+func (f *fakePodInformer) emitUpdate(oldPod, newPod *v1.Pod) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, h := range f.handlers {
+		h.OnUpdate(oldPod, newPod)
+	}
+}
+
+func TestPodWatcherShouldOnlyHandleMatchingPods(t *testing.T) {
+	g := NewWithT(t)
+	informer := newFakePodInformer()
+	w := NewWeeder(context.Background(), testService, testWeederConfig, nil, informer, logr.Discard())
+	defer w.cancelFn()
+
+	var mu sync.Mutex
+	var handled []string
+	pw := newPodWatcher(w, w.dependantSelectors.PodSelectors[0], func(_ context.Context, _ logr.Logger, _ client.Client, targetPod *v1.Pod) (time.Duration, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, targetPod.Name)
+		return 0, nil
+	})
+	go pw.watch()
+	g.Eventually(informer.handlerCount).Should(Equal(1))
+
+	matching := newTestPod("matching", namespace, map[string]string{"gardener.cloud/component": "control-plane"})
+	informer.emitAdd(matching)
+	informer.emitUpdate(matching, matching)
+	informer.emitAdd(newTestPod("other-namespace", "other", map[string]string{"gardener.cloud/component": "control-plane"}))
+	informer.emitAdd(newTestPod("other-labels", namespace, map[string]string{"gardener.cloud/component": "etcd"}))
+
+	mu.Lock()
+	defer mu.Unlock()
+	g.Expect(handled).To(Equal([]string{"matching", "matching"}))
+}
+
+func TestPodWatcherShouldRemoveEventHandlerWhenWeederIsDone(t *testing.T) {
+	g := NewWithT(t)
+	informer := newFakePodInformer()
+	w := NewWeeder(context.Background(), testService, testWeederConfig, nil, informer, logr.Discard())
+
+	pw := newPodWatcher(w, w.dependantSelectors.PodSelectors[0], func(_ context.Context, _ logr.Logger, _ client.Client, _ *v1.Pod) (time.Duration, error) {
+		return 0, nil
+	})
+	done := make(chan struct{})
+	go func() {
+		pw.watch()
+		close(done)
+	}()
+	g.Eventually(informer.handlerCount).Should(Equal(1))
+
+	w.cancelFn()
+	g.Eventually(done).Should(BeClosed())
+	g.Expect(informer.handlerCount()).To(BeZero())
+}
+
+func TestPodWatcherShouldRecheckPodWhenRequestedByHandler(t *testing.T) {
+	g := NewWithT(t)
+	informer := newFakePodInformer()
+	pod := newTestPod("not-ready", namespace, map[string]string{"gardener.cloud/component": "control-plane"})
+	mockClient := mockclient.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().Get(gomock.Any(), client.ObjectKeyFromObject(pod), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+		pod.DeepCopyInto(obj.(*v1.Pod))
+		return nil
+	}).Times(1)
+	w := NewWeeder(context.Background(), testService, testWeederConfig, mockClient, informer, logr.Discard())
+	defer w.cancelFn()
+
+	var handledCount atomic.Int32
+	pw := newPodWatcher(w, w.dependantSelectors.PodSelectors[0], func(_ context.Context, _ logr.Logger, _ client.Client, _ *v1.Pod) (time.Duration, error) {
+		if handledCount.Add(1) == 1 {
+			return 10 * time.Millisecond, nil
+		}
+		return 0, nil
+	})
+	go pw.watch()
+	g.Eventually(informer.handlerCount).Should(Equal(1))
+
+	informer.emitAdd(pod)
+	g.Eventually(handledCount.Load).Should(Equal(int32(2)))
+	g.Consistently(handledCount.Load, 50*time.Millisecond).Should(Equal(int32(2)))
+}
+
+func newTestPod(name, namespace string, labels map[string]string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+	}
+}
@@ -6,40 +6,112 @@ package weeder
 
 import (
 	"context"
+	"slices"
+	"strings"
+	"time"
 
 	wapi "github.com/gardener/dependency-watchdog/api/weeder"
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const crashLoopBackOff = "CrashLoopBackOff"
 
+// podRemovalFunc removes a weedable pod. It returns whether the pod has actually been removed and, if the removal has to be
+// retried, the duration after which it should be retried.
+type podRemovalFunc func(ctx context.Context, log logr.Logger, crClient client.Client, pod *v1.Pod, now time.Time) (removed bool, retryAfter time.Duration, err error)
+
 // Weeder represents an actor which will be responsible for watching dependent pods and weeding them out if they
-// are in CrashLoopBackOff.
+// are stuck in any of the weedable conditions, by default in CrashLoopBackOff.
 type Weeder struct {
 	namespace          string
-	endpoints          *v1.Endpoints
+	serviceName        string
 	ctrlClient         client.Client
-	watchClient        kubernetes.Interface
+	podInformer        cache.Informer
 	dependantSelectors wapi.DependantSelectors
+	weedableConditions wapi.WeedableConditions
+	removePod          podRemovalFunc
 	ctx                context.Context
 	cancelFn           context.CancelFunc
 	logger             logr.Logger
+	// removalQueue queues the weedable pods if their removal is throttled. It is nil if the removal is not throttled.
+	removalQueue *removalQueue
+	// dependencyChain is the chain of services whose recovery has cascaded to this weeder, ending with the service of this weeder.
+	dependencyChain []string
+	// dependantName is the name of the dependant for which the weeder has been started once all its required services are ready.
+	// It is empty if the weeder has been started for a service.
+	dependantName string
+}
+
+type weederOption func(w *Weeder)
+
+// WithDependencyChain configures the weeder with the chain of services whose recovery has cascaded to the service of the weeder.
+// The chain ends with the service of the weeder and is added to the logs of the weeder.
+func WithDependencyChain(chain []string) weederOption {
+	return func(w *Weeder) {
+		w.dependencyChain = chain
+	}
 }
 
-// NewWeeder creates a new Weeder for a service/endpoint.
-func NewWeeder(parentCtx context.Context, namespace string, config *wapi.Config, ctrlClient client.Client, seedClient kubernetes.Interface, ep *v1.Endpoints, logger logr.Logger) *Weeder {
-	wLogger := logger.WithValues("weederRunning", true, "watchDuration", (*config.WatchDuration).String())
-	ctx, cancelFn := context.WithTimeout(parentCtx, config.WatchDuration.Duration)
-	dependantSelectors := config.ServicesAndDependantSelectors[ep.Name]
+// NewWeeder creates a new Weeder for a service. The service is identified independently of whether its readiness has been
+// determined via its Endpoints or its EndpointSlices. The pod informer is shared by all weeders.
+func NewWeeder(parentCtx context.Context, service types.NamespacedName, config *wapi.Config, ctrlClient client.Client, podInformer cache.Informer, logger logr.Logger, options ...weederOption) *Weeder {
+	w := newWeeder(parentCtx, service, config.ServicesAndDependantSelectors[service.Name], config, ctrlClient, podInformer, logger)
+	for _, option := range options {
+		option(w)
+	}
+	if len(w.dependencyChain) == 0 {
+		w.dependencyChain = []string{service.Name}
+	}
+	if len(w.dependencyChain) > 1 {
+		w.logger = w.logger.WithValues("dependencyChain", strings.Join(w.dependencyChain, " -> "))
+	}
+	return w
+}
+
+// NewDependantWeeder creates a new Weeder for a dependant once all its required services are ready. The service is the required
+// service whose readiness has been observed last. The weeder is identified by the dependant, so that it replaces an existing
+// weeder for the dependant independently of which required service has triggered it.
+func NewDependantWeeder(parentCtx context.Context, service types.NamespacedName, dependant wapi.Dependant, config *wapi.Config, ctrlClient client.Client, podInformer cache.Informer, logger logr.Logger) *Weeder {
+	w := newWeeder(parentCtx, service, dependant.DependantSelectors, config, ctrlClient, podInformer, logger)
+	w.dependantName = dependant.Name
+	w.dependencyChain = []string{service.Name}
+	w.logger = w.logger.WithValues("dependant", dependant.Name, "requiredServices", strings.Join(dependant.RequiredServices, ","))
+	return w
+}
+
+func newWeeder(parentCtx context.Context, service types.NamespacedName, dependantSelectors wapi.DependantSelectors, config *wapi.Config, ctrlClient client.Client, podInformer cache.Informer, logger logr.Logger) *Weeder {
+	watchDuration := *config.WatchDuration
+	if dependantSelectors.WatchDuration != nil {
+		watchDuration = *dependantSelectors.WatchDuration
+	}
+	wLogger := logger.WithValues("weederRunning", true, "watchDuration", watchDuration.String())
+	ctx, cancelFn := context.WithTimeout(parentCtx, watchDuration.Duration)
+	weedableConditions := wapi.WeedableConditions{WaitingReasons: []string{crashLoopBackOff}}
+	if dependantSelectors.WeedableConditions != nil {
+		weedableConditions = *dependantSelectors.WeedableConditions
+	}
+	removePod := newPodRemover(config.Eviction).remove
+	if dependantSelectors.Action != nil && *dependantSelectors.Action == wapi.WeederActionRolloutRestart {
+		removePod = newWorkloadRestarter().restart
+	}
+	var queue *removalQueue
+	if dependantSelectors.Throttling != nil {
+		queue = newRemovalQueue(dependantSelectors.Throttling)
+	}
 	return &Weeder{
-		namespace:          namespace,
-		endpoints:          ep,
+		namespace:          service.Namespace,
+		serviceName:        service.Name,
 		ctrlClient:         ctrlClient,
-		watchClient:        seedClient,
+		podInformer:        podInformer,
 		dependantSelectors: dependantSelectors,
+		weedableConditions: weedableConditions,
+		removePod:          removePod,
+		removalQueue:       queue,
 		ctx:                ctx,
 		cancelFn:           cancelFn,
 		logger:             wLogger,
@@ -48,39 +120,130 @@ func NewWeeder(parentCtx context.Context, namespace string, config *wapi.Config,
 
 // Run runs the Weeder which will intern create one go-routine for dependents identified by respective PodSelector.
 func (w *Weeder) Run() {
+	if w.removalQueue != nil {
+		go w.removeQueuedPods()
+	}
 	for _, ps := range w.dependantSelectors.PodSelectors {
-		go newPodWatcher(w, ps, shootPodIfNecessary).watch()
+		go newPodWatcher(w, ps, shootPodIfNecessary(w.weedableConditions, w.removePod, w.removalQueue)).watch()
 	}
 	// weeder should wait till the context expires
 	<-w.ctx.Done()
 }
 
-func shootPodIfNecessary(ctx context.Context, log logr.Logger, crClient client.Client, targetPod *v1.Pod) error {
-	if !shouldDeletePod(targetPod) {
-		return nil
+// shootPodIfNecessary returns a podEventHandler which removes a pod, or restarts its owning workload depending on the
+// configured action, if it satisfies any of the weedable conditions. If the removal is throttled, then the pod is queued
+// instead and removed with one of the next batches. If the pod is not yet weedable but becomes weedable once a container has
+// been waiting or the pod has not been ready for long enough, or if its eviction has been rejected, then the handler returns
+// the duration after which the pod should be checked again.
+func shootPodIfNecessary(conditions wapi.WeedableConditions, removePod podRemovalFunc, queue *removalQueue) podEventHandler {
+	return func(ctx context.Context, log logr.Logger, crClient client.Client, targetPod *v1.Pod) (time.Duration, error) {
+		now := time.Now()
+		weedable, recheckAfter := shouldDeletePod(targetPod, conditions, now)
+		if !weedable {
+			return recheckAfter, nil
+		}
+		if queue != nil {
+			log.Info("Queueing pod for throttled removal", "namespace", targetPod.Namespace, "podName", targetPod.Name)
+			queue.add(targetPod, now)
+			return 0, nil
+		}
+		_, retryAfter, err := removePod(ctx, log, crClient, targetPod, now)
+		return retryAfter, err
 	}
-	log.Info("Deleting pod", "namespace", targetPod.Namespace, "podName", targetPod.Name)
-	return crClient.Delete(ctx, targetPod)
 }
 
-// shouldDeletePod checks if a pod should be deleted for quicker recovery. A pod can be deleted
-// only if it is not marked for deletion and is currently in CrashLoopBackOff state
-func shouldDeletePod(pod *v1.Pod) bool {
-	podNotMarkedForDeletion := pod.DeletionTimestamp == nil
-	return podNotMarkedForDeletion && isPodInCrashloopBackoff(pod.Status)
+// shouldDeletePod checks if a pod should be deleted for quicker recovery. A pod can be deleted only if it is not marked
+// for deletion and satisfies any of the weedable conditions. If the pod is not yet weedable but becomes weedable once a
+// container has been waiting or the pod has not been ready for long enough, then the remaining duration is returned.
+func shouldDeletePod(pod *v1.Pod, conditions wapi.WeedableConditions, now time.Time) (bool, time.Duration) {
+	if pod.DeletionTimestamp != nil {
+		return false, 0
+	}
+	weedable, waitingRecheckAfter := checkContainersInWaitingReason(pod, conditions, now)
+	if weedable {
+		return true, 0
+	}
+	weedable, notReadyRecheckAfter := checkNotReadyFor(pod, conditions.NotReadyFor, now)
+	if weedable {
+		return true, 0
+	}
+	return false, minPositiveDuration(waitingRecheckAfter, notReadyRecheckAfter)
 }
 
-// isPodInCrashloopBackoff checks if any container in a pod is in CrashLoopBackOff
-func isPodInCrashloopBackoff(status v1.PodStatus) bool {
-	for _, containerStatus := range status.ContainerStatuses {
-		if isContainerInCrashLoopBackOff(containerStatus.State) {
-			return true
+// checkContainersInWaitingReason checks if any container in a pod, including its init containers if configured, is waiting for
+// any of the configured reasons, has been restarted at least the configured number of times and has been waiting for at least
+// the configured duration. If a container satisfies all but the waiting duration, then the remaining duration is returned.
+func checkContainersInWaitingReason(pod *v1.Pod, conditions wapi.WeedableConditions, now time.Time) (bool, time.Duration) {
+	containerStatuses := pod.Status.ContainerStatuses
+	if conditions.IncludeInitContainers {
+		containerStatuses = append(slices.Clone(pod.Status.InitContainerStatuses), containerStatuses...)
+	}
+	minRestartCount := int32(0)
+	if conditions.MinRestartCount != nil {
+		minRestartCount = *conditions.MinRestartCount
+	}
+	var recheckAfter time.Duration
+	for _, containerStatus := range containerStatuses {
+		if !isContainerWaitingForAnyReason(containerStatus.State, conditions.WaitingReasons) || containerStatus.RestartCount < minRestartCount {
+			continue
+		}
+		if conditions.MinWaitingDuration == nil {
+			return true, 0
+		}
+		waitingFor := now.Sub(getWaitingSince(pod, containerStatus, now))
+		if waitingFor >= conditions.MinWaitingDuration.Duration {
+			return true, 0
 		}
+		recheckAfter = minPositiveDuration(recheckAfter, conditions.MinWaitingDuration.Duration-waitingFor)
+	}
+	return false, recheckAfter
+}
+
+// getWaitingSince returns the time since which a waiting container is assumed to be waiting, which is the time at which it has
+// last terminated, or the start time of the pod if it has not terminated yet. If neither is known, then the given time is returned.
+func getWaitingSince(pod *v1.Pod, containerStatus v1.ContainerStatus, now time.Time) time.Time {
+	if terminated := containerStatus.LastTerminationState.Terminated; terminated != nil && !terminated.FinishedAt.IsZero() {
+		return terminated.FinishedAt.Time
+	}
+	if pod.Status.StartTime != nil {
+		return pod.Status.StartTime.Time
+	}
+	return now
+}
+
+// minPositiveDuration returns the smaller of the two durations, ignoring durations which are not positive.
+func minPositiveDuration(a, b time.Duration) time.Duration {
+	if a <= 0 {
+		return b
+	}
+	if b <= 0 {
+		return a
 	}
-	return false
+	return min(a, b)
+}
+
+// isContainerWaitingForAnyReason checks if a container is waiting for any of the given reasons
+func isContainerWaitingForAnyReason(containerState v1.ContainerState, reasons []string) bool {
+	return containerState.Waiting != nil && slices.Contains(reasons, containerState.Waiting.Reason)
 }
 
-// isContainerInCrashLoopBackOff checks if a container is in CrashLoopBackOff
-func isContainerInCrashLoopBackOff(containerState v1.ContainerState) bool {
-	return containerState.Waiting != nil && containerState.Waiting.Reason == crashLoopBackOff
+// checkNotReadyFor checks if a running or pending pod has not been ready for at least the given duration. If it has not been
+// ready for a shorter duration, then the remaining duration is returned.
+func checkNotReadyFor(pod *v1.Pod, notReadyFor *metav1.Duration, now time.Time) (bool, time.Duration) {
+	if notReadyFor == nil || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return false, 0
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type != v1.PodReady {
+			continue
+		}
+		if condition.Status == v1.ConditionTrue {
+			return false, 0
+		}
+		if notReadySince := now.Sub(condition.LastTransitionTime.Time); notReadySince < notReadyFor.Duration {
+			return false, notReadyFor.Duration - notReadySince
+		}
+		return true, 0
+	}
+	return false, 0
 }
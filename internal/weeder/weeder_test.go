@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package weeder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	wapi "github.com/gardener/dependency-watchdog/api/weeder"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestShouldDeletePod(t *testing.T) {
+	now := time.Now()
+	defaultConditions := wapi.WeedableConditions{WaitingReasons: []string{crashLoopBackOff}}
+	allConditions := wapi.WeedableConditions{
+		IncludeInitContainers: true,
+		WaitingReasons:        []string{crashLoopBackOff, "CreateContainerError"},
+		MinRestartCount:       pointer.Int32(2),
+		NotReadyFor:           &metav1.Duration{Duration: 5 * time.Minute},
+	}
+	minWaitingConditions := allConditions
+	minWaitingConditions.MinWaitingDuration = &metav1.Duration{Duration: time.Minute}
+
+	table := []struct {
+		description          string
+		pod                  *v1.Pod
+		conditions           wapi.WeedableConditions
+		expectedDelete       bool
+		expectedRecheckAfter time.Duration
+	}{
+		{"container in CrashLoopBackOff", createPodWithStatus(withContainerWaiting(crashLoopBackOff, 0)), defaultConditions, true, 0},
+		{"pod marked for deletion", markForDeletion(createPodWithStatus(withContainerWaiting(crashLoopBackOff, 0))), defaultConditions, false, 0},
+		{"healthy pod", createPodWithStatus(withReadyCondition(v1.ConditionTrue, now.Add(-time.Hour))), defaultConditions, false, 0},
+		{"init container in CrashLoopBackOff is ignored by default", createPodWithStatus(withInitContainerWaiting(crashLoopBackOff, 3)), defaultConditions, false, 0},
+		{"init container in CrashLoopBackOff", createPodWithStatus(withInitContainerWaiting(crashLoopBackOff, 3)), allConditions, true, 0},
+		{"container waiting for a configured reason", createPodWithStatus(withContainerWaiting("CreateContainerError", 2)), allConditions, true, 0},
+		{"container waiting for a reason which is not configured", createPodWithStatus(withContainerWaiting("ImagePullBackOff", 5)), allConditions, false, 0},
+		{"container in CrashLoopBackOff with too few restarts", createPodWithStatus(withContainerWaiting(crashLoopBackOff, 1)), allConditions, false, 0},
+		{"not ready for too long", createPodWithStatus(withReadyCondition(v1.ConditionFalse, now.Add(-6*time.Minute))), allConditions, true, 0},
+		{"not ready for a short while", createPodWithStatus(withReadyCondition(v1.ConditionFalse, now.Add(-2*time.Minute))), allConditions, false, 3 * time.Minute},
+		{"not ready is ignored by default", createPodWithStatus(withReadyCondition(v1.ConditionFalse, now.Add(-6*time.Minute))), defaultConditions, false, 0},
+		{"container in CrashLoopBackOff for long enough", createPodWithStatus(withContainerWaitingSince(crashLoopBackOff, 2, now.Add(-2*time.Minute))), minWaitingConditions, true, 0},
+		{"container in CrashLoopBackOff for a short while", createPodWithStatus(withContainerWaitingSince(crashLoopBackOff, 2, now.Add(-30*time.Second))), minWaitingConditions, false, 30 * time.Second},
+		{"container in CrashLoopBackOff for a short while with too few restarts", createPodWithStatus(withContainerWaitingSince(crashLoopBackOff, 1, now.Add(-30*time.Second))), minWaitingConditions, false, 0},
+		{"container which has not terminated yet is waiting since the pod has started", createPodWithStatus(withContainerWaiting("CreateContainerError", 2), withStartTime(now.Add(-2*time.Minute))), minWaitingConditions, true, 0},
+		{"earliest recheck of waiting container and not ready pod", createPodWithStatus(withContainerWaitingSince(crashLoopBackOff, 2, now.Add(-30*time.Second)), withReadyCondition(v1.ConditionFalse, now.Add(-4*time.Minute-50*time.Second))), minWaitingConditions, false, 10 * time.Second},
+		{"completed pod which is not ready", createPodWithStatus(withReadyCondition(v1.ConditionFalse, now.Add(-6*time.Minute)), withPhase(v1.PodSucceeded)), allConditions, false, 0},
+	}
+
+	for _, entry := range table {
+		t.Run(entry.description, func(t *testing.T) {
+			g := NewWithT(t)
+			shouldDelete, recheckAfter := shouldDeletePod(entry.pod, entry.conditions, now)
+			g.Expect(shouldDelete).To(Equal(entry.expectedDelete))
+			g.Expect(recheckAfter).To(Equal(entry.expectedRecheckAfter))
+		})
+	}
+}
+
+type podStatusOption func(status *v1.PodStatus)
+
+func createPodWithStatus(options ...podStatusOption) *v1.Pod {
+	pod := newTestPod("test-pod", namespace, nil)
+	pod.Status.Phase = v1.PodRunning
+	for _, option := range options {
+		option(&pod.Status)
+	}
+	return pod
+}
+
+func withContainerWaiting(reason string, restartCount int32) podStatusOption {
+	return func(status *v1.PodStatus) {
+		status.ContainerStatuses = append(status.ContainerStatuses, createWaitingContainerStatus(reason, restartCount))
+	}
+}
+
+func withContainerWaitingSince(reason string, restartCount int32, lastTerminatedAt time.Time) podStatusOption {
+	return func(status *v1.PodStatus) {
+		containerStatus := createWaitingContainerStatus(reason, restartCount)
+		containerStatus.LastTerminationState.Terminated = &v1.ContainerStateTerminated{FinishedAt: metav1.NewTime(lastTerminatedAt)}
+		status.ContainerStatuses = append(status.ContainerStatuses, containerStatus)
+	}
+}
+
+func withStartTime(startTime time.Time) podStatusOption {
+	return func(status *v1.PodStatus) {
+		status.StartTime = &metav1.Time{Time: startTime}
+	}
+}
+
+func withInitContainerWaiting(reason string, restartCount int32) podStatusOption {
+	return func(status *v1.PodStatus) {
+		status.InitContainerStatuses = append(status.InitContainerStatuses, createWaitingContainerStatus(reason, restartCount))
+	}
+}
+
+func withReadyCondition(conditionStatus v1.ConditionStatus, lastTransitionTime time.Time) podStatusOption {
+	return func(status *v1.PodStatus) {
+		status.Conditions = append(status.Conditions, v1.PodCondition{
+			Type:               v1.PodReady,
+			Status:             conditionStatus,
+			LastTransitionTime: metav1.NewTime(lastTransitionTime),
+		})
+	}
+}
+
+func withPhase(phase v1.PodPhase) podStatusOption {
+	return func(status *v1.PodStatus) {
+		status.Phase = phase
+	}
+}
+
+func createWaitingContainerStatus(reason string, restartCount int32) v1.ContainerStatus {
+	return v1.ContainerStatus{
+		Name:         "test-container",
+		State:        v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: reason}},
+		RestartCount: restartCount,
+	}
+}
+
+func markForDeletion(pod *v1.Pod) *v1.Pod {
+	now := metav1.Now()
+	pod.DeletionTimestamp = &now
+	return pod
+}
+
+func TestNewWeederShouldUseWatchDurationOfService(t *testing.T) {
+	g := NewWithT(t)
+	config := &wapi.Config{
+		WatchDuration: &metav1.Duration{Duration: time.Minute},
+		ServicesAndDependantSelectors: map[string]wapi.DependantSelectors{
+			epName:           {WatchDuration: &metav1.Duration{Duration: time.Hour}},
+			"kube-apiserver": {},
+		},
+	}
+	for svcName, expectedWatchDuration := range map[string]time.Duration{epName: time.Hour, "kube-apiserver": time.Minute} {
+		w := NewWeeder(context.Background(), types.NamespacedName{Namespace: namespace, Name: svcName}, config, nil, nil, logr.Discard())
+		deadline, ok := w.ctx.Deadline()
+		g.Expect(ok).To(BeTrue())
+		g.Expect(time.Until(deadline)).To(BeNumerically("~", expectedWatchDuration, time.Second), "unexpected watch duration for service %s", svcName)
+		w.cancelFn()
+	}
+}
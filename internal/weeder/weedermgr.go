@@ -6,7 +6,10 @@ package weeder
 
 import (
 	"context"
+	"slices"
 	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // Manager provides a single point for registering and unregistering weeders
@@ -21,6 +24,9 @@ type Manager interface {
 	UnregisterAll()
 	// GetWeederRegistration returns a weederRegistration which will give access to the context and the cancelFn to the caller.
 	GetWeederRegistration(key string) (Registration, bool)
+	// GetDependencyChain returns the chain of services whose recovery cascades to the given service, ending with the given service.
+	// If no weeder is running for a service on which the given service depends, then the chain only consists of the given service.
+	GetDependencyChain(service types.NamespacedName) []string
 }
 
 // Registration provides a handle to check if a weeder has been closed and to also close the weeder.
@@ -34,6 +40,14 @@ type Registration interface {
 type weederManager struct {
 	sync.Mutex
 	weeders map[string]weederRegistration
+	// chains are the dependency chains for the dependant services of the registered weeders, keyed by the dependant service.
+	chains map[string]dependencyChain
+}
+
+// dependencyChain captures a chain of services which is valid as long as the weeder of the last but one service is running.
+type dependencyChain struct {
+	ctx      context.Context
+	services []string
 }
 
 // weederRegistration captures the handle to manage a weeder
@@ -71,6 +85,13 @@ func (wm *weederManager) Register(weeder Weeder) bool {
 		ctx:      weeder.ctx,
 		cancelFn: weeder.cancelFn,
 	}
+	wm.pruneExpiredChains()
+	for _, dependantService := range weeder.dependantSelectors.DependantServices {
+		wm.chains[weeder.namespace+"/"+dependantService] = dependencyChain{
+			ctx:      weeder.ctx,
+			services: append(slices.Clone(weeder.dependencyChain), dependantService),
+		}
+	}
 	return true
 }
 
@@ -78,6 +99,7 @@ func (wm *weederManager) Register(weeder Weeder) bool {
 func NewManager() Manager {
 	return &weederManager{
 		weeders: make(map[string]weederRegistration),
+		chains:  make(map[string]dependencyChain),
 	}
 }
 
@@ -87,11 +109,22 @@ func (wm *weederManager) Unregister(key string) bool {
 	if wr, ok := wm.weeders[key]; ok {
 		delete(wm.weeders, key)
 		wr.Close()
+		wm.pruneExpiredChains()
 		return true
 	}
 	return false
 }
 
+// pruneExpiredChains removes the dependency chains whose upstream weeder is no longer running, so that the chains of services
+// which are never looked up again do not pile up. It must be called with the lock held.
+func (wm *weederManager) pruneExpiredChains() {
+	for key, chain := range wm.chains {
+		if chain.ctx.Err() != nil {
+			delete(wm.chains, key)
+		}
+	}
+}
+
 func (wm *weederManager) UnregisterAll() {
 	for key := range wm.weeders {
 		_ = wm.Unregister(key)
@@ -103,7 +136,24 @@ func (wm *weederManager) GetWeederRegistration(key string) (Registration, bool)
 	return wr, ok
 }
 
-// createKey creates a key to uniquely identify a weeder
+func (wm *weederManager) GetDependencyChain(service types.NamespacedName) []string {
+	wm.Lock()
+	defer wm.Unlock()
+	key := service.String()
+	if chain, ok := wm.chains[key]; ok {
+		if chain.ctx.Err() == nil {
+			return slices.Clone(chain.services)
+		}
+		delete(wm.chains, key)
+	}
+	return []string{service.Name}
+}
+
+// createKey creates a key to uniquely identify a weeder. The weeder of a dependant is identified by the dependant instead of
+// the service, as a slash is not allowed in the name of a service the keys of the two kinds of weeders cannot collide.
 func createKey(w Weeder) string {
-	return w.namespace + "/" + w.endpoints.Name
+	if w.dependantName != "" {
+		return w.namespace + "/dependant/" + w.dependantName
+	}
+	return w.namespace + "/" + w.serviceName
 }
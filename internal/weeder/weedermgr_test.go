@@ -14,8 +14,8 @@ import (
 	v12 "github.com/gardener/dependency-watchdog/api/weeder"
 	"github.com/go-logr/logr"
 	. "github.com/onsi/gomega"
-	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 const (
@@ -30,9 +30,7 @@ var (
 		WatchDuration:                 &metav1.Duration{Duration: testWatchDuration},
 		ServicesAndDependantSelectors: testServicesAndDependantSelectors,
 	}
-	testEp = &v1.Endpoints{
-		ObjectMeta: metav1.ObjectMeta{Name: epName},
-	}
+	testService = types.NamespacedName{Namespace: namespace, Name: epName}
 )
 
 func setupMgrTest(t *testing.T) (Manager, func(mgr Manager)) {
@@ -49,7 +47,7 @@ func TestRegisterNewWeederAndIsNotClosed(t *testing.T) {
 	mgr, tearDownTest := setupMgrTest(t)
 	defer tearDownTest(mgr)
 
-	w := NewWeeder(context.Background(), namespace, testWeederConfig, nil, nil, testEp, logr.Discard())
+	w := NewWeeder(context.Background(), testService, testWeederConfig, nil, nil, logr.Discard())
 	g.Expect(w).ShouldNot(BeNil(), "NewWeeder should have returned a non nil weeder")
 	g.Expect(mgr.Register(*w)).To(BeTrue(), "mgr.Register should register a new weeder")
 
@@ -66,13 +64,13 @@ func TestRegisterWeederWithSameKeyShouldReplaceOldEntry(t *testing.T) {
 	mgr, tearDownTest := setupMgrTest(t)
 	defer tearDownTest(mgr)
 
-	w1 := NewWeeder(context.Background(), namespace, testWeederConfig, nil, nil, testEp, logr.Discard())
+	w1 := NewWeeder(context.Background(), testService, testWeederConfig, nil, nil, logr.Discard())
 	g.Expect(mgr.Register(*w1)).To(BeTrue(), "mgr.Register should register the first weeder")
 	key := createKey(*w1)
 	foundWeederRegistration1, _ := mgr.GetWeederRegistration(key)
 	g.Expect(foundWeederRegistration1.IsClosed()).To(BeFalse(), "First Registered weeder should be alive")
 
-	w2 := NewWeeder(context.Background(), namespace, testWeederConfig, nil, nil, testEp, logr.Discard())
+	w2 := NewWeeder(context.Background(), testService, testWeederConfig, nil, nil, logr.Discard())
 	g.Expect(mgr.Register(*w2)).To(BeTrue(), "mgr.Register should register the second weeder")
 	foundWeederRegistration2, _ := mgr.GetWeederRegistration(key)
 
@@ -87,7 +85,7 @@ func TestUnregisterExistingWeederShouldCloseItAndRemoveItFromManager(t *testing.
 	mgr, tearDownTest := setupMgrTest(t)
 	defer tearDownTest(mgr)
 
-	w := NewWeeder(context.Background(), namespace, testWeederConfig, nil, nil, testEp, logr.Discard())
+	w := NewWeeder(context.Background(), testService, testWeederConfig, nil, nil, logr.Discard())
 	g.Expect(mgr.Register(*w)).To(BeTrue(), "mgr.Register should register the first weeder")
 	key := createKey(*w)
 	foundWeederRegistration, _ := mgr.GetWeederRegistration(key)
@@ -109,3 +107,83 @@ func TestUnregisterNonExistingWeederShouldNotFail(t *testing.T) {
 	g.Expect(mgr.Unregister("random-key")).To(BeFalse(), "mgr.Unregister should return false for non existing weeder")
 	t.Log("De-registering a non-existing weeder did not fail")
 }
+
+func TestDependencyChainShouldCascadeAlongDependantServices(t *testing.T) {
+	g := NewWithT(t)
+	mgr, tearDownTest := setupMgrTest(t)
+	defer tearDownTest(mgr)
+
+	const (
+		kubeAPIServer    = "kube-apiserver"
+		kubeStateMetrics = "kube-state-metrics"
+	)
+	config := &v12.Config{
+		WatchDuration: &metav1.Duration{Duration: testWatchDuration},
+		ServicesAndDependantSelectors: map[string]v12.DependantSelectors{
+			epName:        {DependantServices: []string{kubeAPIServer}},
+			kubeAPIServer: {DependantServices: []string{kubeStateMetrics}},
+		},
+	}
+	apiServerService := types.NamespacedName{Namespace: namespace, Name: kubeAPIServer}
+	g.Expect(mgr.GetDependencyChain(apiServerService)).To(Equal([]string{kubeAPIServer}), "a service without a running upstream weeder should not be part of a chain")
+
+	etcdWeeder := NewWeeder(context.Background(), testService, config, nil, nil, logr.Discard(), WithDependencyChain(mgr.GetDependencyChain(testService)))
+	mgr.Register(*etcdWeeder)
+	apiServerChain := mgr.GetDependencyChain(apiServerService)
+	g.Expect(apiServerChain).To(Equal([]string{epName, kubeAPIServer}))
+	g.Expect(mgr.GetDependencyChain(types.NamespacedName{Namespace: "other", Name: kubeAPIServer})).To(Equal([]string{kubeAPIServer}), "chains should not cascade across namespaces")
+
+	apiServerWeeder := NewWeeder(context.Background(), apiServerService, config, nil, nil, logr.Discard(), WithDependencyChain(apiServerChain))
+	mgr.Register(*apiServerWeeder)
+	g.Expect(mgr.GetDependencyChain(types.NamespacedName{Namespace: namespace, Name: kubeStateMetrics})).To(Equal([]string{epName, kubeAPIServer, kubeStateMetrics}))
+
+	etcdWeeder.cancelFn()
+	g.Expect(mgr.GetDependencyChain(apiServerService)).To(Equal([]string{kubeAPIServer}), "a chain should end once the upstream weeder is done")
+}
+
+func TestExpiredDependencyChainsShouldBePrunedOnRegisterAndUnregister(t *testing.T) {
+	g := NewWithT(t)
+	mgr, tearDownTest := setupMgrTest(t)
+	defer tearDownTest(mgr)
+	wm := mgr.(*weederManager)
+
+	config := &v12.Config{
+		WatchDuration:                 &metav1.Duration{Duration: testWatchDuration},
+		ServicesAndDependantSelectors: map[string]v12.DependantSelectors{epName: {DependantServices: []string{"kube-apiserver"}}},
+	}
+	etcdWeeder := NewWeeder(context.Background(), testService, config, nil, nil, logr.Discard())
+	g.Expect(mgr.Register(*etcdWeeder)).To(BeTrue())
+	g.Expect(wm.chains).To(HaveLen(1))
+	g.Expect(mgr.Unregister(createKey(*etcdWeeder))).To(BeTrue())
+	g.Expect(wm.chains).To(BeEmpty(), "the chains of an unregistered weeder should be pruned")
+
+	etcdWeeder = NewWeeder(context.Background(), testService, config, nil, nil, logr.Discard())
+	g.Expect(mgr.Register(*etcdWeeder)).To(BeTrue())
+	etcdWeeder.cancelFn()
+	otherWeeder := NewWeeder(context.Background(), types.NamespacedName{Namespace: "other", Name: epName}, testWeederConfig, nil, nil, logr.Discard())
+	g.Expect(mgr.Register(*otherWeeder)).To(BeTrue())
+	g.Expect(wm.chains).To(BeEmpty(), "the chains of a weeder which is done should be pruned without being looked up")
+}
+
+func TestDependantWeederShouldNotReplaceWeederOfService(t *testing.T) {
+	g := NewWithT(t)
+	mgr, tearDownTest := setupMgrTest(t)
+	defer tearDownTest(mgr)
+
+	serviceWeeder := NewWeeder(context.Background(), testService, testWeederConfig, nil, nil, logr.Discard())
+	g.Expect(mgr.Register(*serviceWeeder)).To(BeTrue())
+	dependant := v12.Dependant{Name: epName, RequiredServices: []string{epName, "etcd-events"}, DependantSelectors: testServicesAndDependantSelectors[epName]}
+	dependantWeeder1 := NewDependantWeeder(context.Background(), testService, dependant, testWeederConfig, nil, nil, logr.Discard())
+	g.Expect(mgr.Register(*dependantWeeder1)).To(BeTrue())
+	g.Expect(createKey(*dependantWeeder1)).ToNot(Equal(createKey(*serviceWeeder)), "the weeder of a dependant should not have the same key as the weeder of a service with the same name")
+
+	// the dependant weeder is replaced, independently of which required service has triggered it
+	dependantWeeder2 := NewDependantWeeder(context.Background(), types.NamespacedName{Namespace: namespace, Name: "etcd-events"}, dependant, testWeederConfig, nil, nil, logr.Discard())
+	g.Expect(mgr.Register(*dependantWeeder2)).To(BeTrue())
+	g.Expect(createKey(*dependantWeeder2)).To(Equal(createKey(*dependantWeeder1)))
+
+	serviceRegistration, _ := mgr.GetWeederRegistration(createKey(*serviceWeeder))
+	g.Expect(serviceRegistration.IsClosed()).To(BeFalse(), "the weeder of the service should still be alive")
+	g.Expect(dependantWeeder1.ctx.Err()).To(HaveOccurred(), "the first weeder of the dependant should be cancelled")
+	g.Expect(dependantWeeder2.ctx.Err()).ToNot(HaveOccurred(), "the second weeder of the dependant should be alive")
+}
@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scaleprotection
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// WebhookPath is the path at which the scale protection webhook is served.
+	WebhookPath = "/webhooks/validate-scale-protection"
+	// scaleSubResource is the name of the scale subresource of scalable resources.
+	scaleSubResource = "scale"
+	// defaultReplicas is the value of spec.replicas if it is not set for a resource.
+	defaultReplicas int32 = 1
+)
+
+// Handler is a validating admission webhook which denies increasing the replicas of a resource which is held down by DWD.
+// It prevents other actors from scaling up a resource while the probe which has scaled it down still fails, which would
+// otherwise result in a tug-of-war with the probe. Scaling up a resource can be enforced by setting the allow-scale-up
+// annotation on it, which only bypasses the webhook, or the ignore-scaling annotation, which also stops DWD from scaling the resource.
+type Handler struct {
+	// Client is used to read the annotations of a resource when its scale subresource is updated.
+	Client client.Reader
+	// RESTMapper is used to determine the kind of resource whose scale subresource is updated.
+	RESTMapper meta.RESTMapper
+	// Decoder decodes the objects of admission requests.
+	Decoder *admission.Decoder
+	// ExemptUsernames are the users which are allowed to scale up resources held down by DWD, typically DWD itself.
+	ExemptUsernames sets.Set[string]
+	// Logger is the logger used by the handler.
+	Logger logr.Logger
+}
+
+// AddToManager registers the Handler with the webhook server of the manager.
+func (h *Handler) AddToManager(mgr manager.Manager) {
+	mgr.GetWebhookServer().Register(WebhookPath, &webhook.Admission{Handler: h})
+}
+
+// Handle validates an update of a scalable resource or of its scale subresource.
+func (h *Handler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Update {
+		return admission.Allowed("only updates can scale up a resource")
+	}
+	if h.ExemptUsernames.Has(req.UserInfo.Username) {
+		return admission.Allowed("user is exempted from scale protection")
+	}
+	change, err := h.getReplicaChange(ctx, req)
+	if err != nil {
+		h.Logger.Error(err, "Failed to determine the change in replicas", "namespace", req.Namespace, "name", req.Name, "resource", req.Resource, "subResource", req.SubResource)
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if change.newReplicas <= change.oldReplicas {
+		return admission.Allowed("replicas are not increased")
+	}
+	if !scaler.IsHeldDown(change.oldAnnotations, change.oldReplicas) {
+		return admission.Allowed("resource is not held down by dependency-watchdog")
+	}
+	if scaler.IsScaleUpAllowed(change.newAnnotations) {
+		h.Logger.Info("Allowing scale up of resource held down by dependency-watchdog as scale up is allowed for it", "namespace", req.Namespace, "name", req.Name, "resource", req.Resource, "user", req.UserInfo.Username)
+		return admission.Allowed("scale up is allowed for the resource")
+	}
+	if scaler.IsScalingIgnored(change.newAnnotations) {
+		h.Logger.Info("Allowing scale up of resource held down by dependency-watchdog as scaling is ignored for it", "namespace", req.Namespace, "name", req.Name, "resource", req.Resource, "user", req.UserInfo.Username)
+		return admission.Allowed("scaling is ignored for the resource")
+	}
+	h.Logger.Info("Denying scale up of resource held down by dependency-watchdog", "namespace", req.Namespace, "name", req.Name, "resource", req.Resource, "user", req.UserInfo.Username)
+	return admission.Denied(fmt.Sprintf("%s %s/%s has been scaled down by dependency-watchdog as the shoot control plane cannot reach its nodes. "+
+		"It will be scaled up by dependency-watchdog once the nodes are reachable again. To scale it up nevertheless, set the annotation %s=true on it. "+
+		"To also stop dependency-watchdog from scaling it, set the annotation %s=true instead", req.Resource.Resource, req.Namespace, req.Name, scaler.AllowScaleUpAnnotationKey, scaler.IgnoreScalingAnnotationKey))
+}
+
+// replicaChange captures the replicas and the annotations of a resource before and after an update.
+type replicaChange struct {
+	oldReplicas    int32
+	newReplicas    int32
+	oldAnnotations map[string]string
+	newAnnotations map[string]string
+}
+
+func (h *Handler) getReplicaChange(ctx context.Context, req admission.Request) (*replicaChange, error) {
+	if req.SubResource == scaleSubResource {
+		return h.getScaleSubResourceReplicaChange(ctx, req)
+	}
+	if req.SubResource != "" {
+		return &replicaChange{}, nil
+	}
+	oldObj, newObj := &unstructured.Unstructured{}, &unstructured.Unstructured{}
+	if err := h.Decoder.DecodeRaw(req.OldObject, oldObj); err != nil {
+		return nil, err
+	}
+	if err := h.Decoder.DecodeRaw(req.Object, newObj); err != nil {
+		return nil, err
+	}
+	oldReplicas, err := getSpecReplicas(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newReplicas, err := getSpecReplicas(newObj)
+	if err != nil {
+		return nil, err
+	}
+	return &replicaChange{
+		oldReplicas:    oldReplicas,
+		newReplicas:    newReplicas,
+		oldAnnotations: oldObj.GetAnnotations(),
+		newAnnotations: newObj.GetAnnotations(),
+	}, nil
+}
+
+// getScaleSubResourceReplicaChange determines the replica change for an update of the scale subresource. The scale subresource does
+// not carry the annotations of the resource, therefore they are read from the resource itself.
+func (h *Handler) getScaleSubResourceReplicaChange(ctx context.Context, req admission.Request) (*replicaChange, error) {
+	oldScale, newScale := &autoscalingv1.Scale{}, &autoscalingv1.Scale{}
+	if err := h.Decoder.DecodeRaw(req.OldObject, oldScale); err != nil {
+		return nil, err
+	}
+	if err := h.Decoder.DecodeRaw(req.Object, newScale); err != nil {
+		return nil, err
+	}
+	change := &replicaChange{
+		oldReplicas: oldScale.Spec.Replicas,
+		newReplicas: newScale.Spec.Replicas,
+	}
+	if change.newReplicas <= change.oldReplicas {
+		return change, nil
+	}
+	gvk, err := h.RESTMapper.KindFor(schema.GroupVersionResource{Group: req.Resource.Group, Version: req.Resource.Version, Resource: req.Resource.Resource})
+	if err != nil {
+		return nil, err
+	}
+	partialObjMeta := &metav1.PartialObjectMetadata{}
+	partialObjMeta.SetGroupVersionKind(gvk)
+	if err = h.Client.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: req.Name}, partialObjMeta); err != nil {
+		return nil, err
+	}
+	change.oldAnnotations = partialObjMeta.Annotations
+	change.newAnnotations = partialObjMeta.Annotations
+	return change, nil
+}
+
+func getSpecReplicas(obj *unstructured.Unstructured) (int32, error) {
+	replicas, found, err := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return defaultReplicas, nil
+	}
+	return int32(replicas), nil
+}
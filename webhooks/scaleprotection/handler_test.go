@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !kind_tests
+
+package scaleprotection
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mockclient "github.com/gardener/dependency-watchdog/internal/mock/controller-runtime/client"
+	"github.com/gardener/dependency-watchdog/internal/prober/scaler"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	testNamespace    = "shoot--test--handler"
+	testName         = "kube-controller-manager"
+	testDWDUsername  = "system:serviceaccount:garden:dependency-watchdog-prober"
+	testUserUsername = "operator"
+)
+
+var (
+	heldDownAnnotations = map[string]string{scaler.ReplicasAnnotationKey: "2"}
+	ignoreAnnotations   = map[string]string{scaler.ReplicasAnnotationKey: "2", scaler.IgnoreScalingAnnotationKey: "true"}
+	allowAnnotations    = map[string]string{scaler.ReplicasAnnotationKey: "2", scaler.AllowScaleUpAnnotationKey: "true"}
+	deploymentsResource = metav1.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+)
+
+func TestHandleDeploymentUpdate(t *testing.T) {
+	tests := []struct {
+		title          string
+		username       string
+		oldAnnotations map[string]string
+		newAnnotations map[string]string
+		oldReplicas    *int32
+		newReplicas    *int32
+		expectAllowed  bool
+	}{
+		{"scale up of held down resource is denied", testUserUsername, heldDownAnnotations, heldDownAnnotations, pointer.Int32(0), pointer.Int32(2), false},
+		{"scale up of held down resource by DWD is allowed", testDWDUsername, heldDownAnnotations, heldDownAnnotations, pointer.Int32(0), pointer.Int32(2), true},
+		{"scale up of held down resource with ignore scaling annotation is allowed", testUserUsername, heldDownAnnotations, ignoreAnnotations, pointer.Int32(0), pointer.Int32(2), true},
+		{"scale up of held down resource with allow scale up annotation is allowed", testUserUsername, heldDownAnnotations, allowAnnotations, pointer.Int32(0), pointer.Int32(2), true},
+		{"scale up of held down resource with allow scale up annotation set to false is denied", testUserUsername, heldDownAnnotations, map[string]string{scaler.ReplicasAnnotationKey: "2", scaler.AllowScaleUpAnnotationKey: "false"}, pointer.Int32(0), pointer.Int32(2), false},
+		{"scale up of resource without replicas annotation is allowed", testUserUsername, nil, nil, pointer.Int32(0), pointer.Int32(2), true},
+		{"scale up of resource with replicas annotation and non-zero replicas is allowed", testUserUsername, heldDownAnnotations, heldDownAnnotations, pointer.Int32(1), pointer.Int32(2), true},
+		{"scale up of held down resource by unsetting replicas is denied", testUserUsername, heldDownAnnotations, heldDownAnnotations, pointer.Int32(0), nil, false},
+		{"update of held down resource without replica change is allowed", testUserUsername, heldDownAnnotations, nil, pointer.Int32(0), pointer.Int32(0), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			g := NewWithT(t)
+			h := createHandler(t, nil)
+			req := createRequest(g, test.username, "", createDeployment(test.oldAnnotations, test.oldReplicas), createDeployment(test.newAnnotations, test.newReplicas))
+			resp := h.Handle(context.Background(), req)
+			g.Expect(resp.Allowed).To(Equal(test.expectAllowed))
+		})
+	}
+}
+
+func TestHandleScaleSubResourceUpdate(t *testing.T) {
+	tests := []struct {
+		title         string
+		annotations   map[string]string
+		oldReplicas   int32
+		newReplicas   int32
+		expectGet     bool
+		expectAllowed bool
+	}{
+		{"scale up of held down resource is denied", heldDownAnnotations, 0, 2, true, false},
+		{"scale up of held down resource with ignore scaling annotation is allowed", ignoreAnnotations, 0, 2, true, true},
+		{"scale up of held down resource with allow scale up annotation is allowed", allowAnnotations, 0, 2, true, true},
+		{"scale up of resource without replicas annotation is allowed", nil, 0, 2, true, true},
+		{"scale down of held down resource is allowed without reading the resource", heldDownAnnotations, 2, 0, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			g := NewWithT(t)
+			mockReader := mockclient.NewMockClient(gomock.NewController(t))
+			if test.expectGet {
+				mockReader.EXPECT().Get(gomock.Any(), client.ObjectKey{Namespace: testNamespace, Name: testName}, gomock.Any()).DoAndReturn(func(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+					g.Expect(obj.GetObjectKind().GroupVersionKind()).To(Equal(appsv1.SchemeGroupVersion.WithKind("Deployment")))
+					obj.SetAnnotations(test.annotations)
+					return nil
+				}).Times(1)
+			}
+			h := createHandler(t, mockReader)
+			req := createRequest(g, testUserUsername, scaleSubResource, createScale(test.oldReplicas), createScale(test.newReplicas))
+			resp := h.Handle(context.Background(), req)
+			g.Expect(resp.Allowed).To(Equal(test.expectAllowed))
+		})
+	}
+}
+
+func TestHandleNonUpdateOperationIsAllowed(t *testing.T) {
+	g := NewWithT(t)
+	h := createHandler(t, nil)
+	req := createRequest(g, testUserUsername, "", createDeployment(heldDownAnnotations, pointer.Int32(0)), createDeployment(heldDownAnnotations, pointer.Int32(2)))
+	req.Operation = admissionv1.Create
+	g.Expect(h.Handle(context.Background(), req).Allowed).To(BeTrue())
+}
+
+func createHandler(t *testing.T, reader client.Reader) *Handler {
+	scheme := runtime.NewScheme()
+	NewWithT(t).Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	restMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{appsv1.SchemeGroupVersion})
+	restMapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+	return &Handler{
+		Client:          reader,
+		RESTMapper:      restMapper,
+		Decoder:         admission.NewDecoder(scheme),
+		ExemptUsernames: sets.New[string](testDWDUsername),
+		Logger:          logr.Discard(),
+	}
+}
+
+func createRequest(g *WithT, username, subResource string, oldObj, newObj runtime.Object) admission.Request {
+	oldRaw, err := json.Marshal(oldObj)
+	g.Expect(err).ToNot(HaveOccurred())
+	newRaw, err := json.Marshal(newObj)
+	g.Expect(err).ToNot(HaveOccurred())
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation:   admissionv1.Update,
+			Namespace:   testNamespace,
+			Name:        testName,
+			Resource:    deploymentsResource,
+			SubResource: subResource,
+			UserInfo:    authenticationv1.UserInfo{Username: username},
+			OldObject:   runtime.RawExtension{Raw: oldRaw},
+			Object:      runtime.RawExtension{Raw: newRaw},
+		},
+	}
+}
+
+func createDeployment(annotations map[string]string, replicas *int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Annotations: annotations},
+		Spec:       appsv1.DeploymentSpec{Replicas: replicas},
+	}
+}
+
+func createScale(replicas int32) *autoscalingv1.Scale {
+	return &autoscalingv1.Scale{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "autoscaling/v1", Kind: "Scale"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+		Spec:       autoscalingv1.ScaleSpec{Replicas: replicas},
+	}
+}